@@ -40,6 +40,16 @@ const (
 	WaitingForBootstrapDataReason = "WaitingForBootstrapData"
 )
 
+const (
+	// ELBAttachedCondition will report true when a control plane is successfully registered with the load balancers.
+	// When set to false, severity can be an Error if the subnet is not found or unavailable in the instance's AZ.
+	ELBAttachedCondition clusterv1.ConditionType = "ELBAttached"
+	// ELBAttachFailedReason used when a control plane node fails to attach to the load balancers.
+	ELBAttachFailedReason = "ELBAttachFailed"
+	// ELBDetachFailedReason used when a control plane node fails to detach from the load balancers.
+	ELBDetachFailedReason = "ELBDetachFailed"
+)
+
 const (
 	// SecurityGroupsReadyCondition indicates the security groups are up to date on the HuaweiCloudMachine.
 	SecurityGroupsReadyCondition clusterv1.ConditionType = "SecurityGroupsReady"
@@ -56,6 +66,8 @@ const (
 	VpcCreationStartedReason = "VpcCreationStarted"
 	// VpcReconciliationFailedReason used when errors occur during VPC reconciliation.
 	VpcReconciliationFailedReason = "VpcReconciliationFailed"
+	// VpcNotFoundReason used when the VPC recorded in the cluster spec no longer exists.
+	VpcNotFoundReason = "VpcNotFound"
 )
 
 const (
@@ -77,6 +89,26 @@ const (
 	LoadBalancerReadyCondition clusterv1.ConditionType = "LoadBalancerReady"
 	// LoadBalancerFailedReason used when an error occurs during load balancer reconciliation.
 	LoadBalancerFailedReason = "LoadBalancerFailed"
+	// ControlPlaneEndpointNotSetReason used when the load balancer is disabled and no control plane endpoint is provided.
+	ControlPlaneEndpointNotSetReason = "ControlPlaneEndpointNotSet"
+	// LoadBalancerNotFoundReason used when the load balancer no longer exists and cannot be re-created
+	// without changing the control plane endpoint.
+	LoadBalancerNotFoundReason = "LoadBalancerNotFound"
+
+	// LoadBalancerMembersHealthyCondition reports on whether the backend servers of the load balancer pools are online.
+	LoadBalancerMembersHealthyCondition clusterv1.ConditionType = "LoadBalancerMembersHealthy"
+	// LoadBalancerMembersUnhealthyReason used when some backend servers are reported offline by the health monitors.
+	LoadBalancerMembersUnhealthyReason = "LoadBalancerMembersUnhealthy"
+)
+
+const (
+	// InfrastructureDeletedCondition reports on the progress of the deletion of the cluster infrastructure.
+	InfrastructureDeletedCondition clusterv1.ConditionType = "InfrastructureDeleted"
+	// WaitingForMachinesDeletionReason used while the HuaweiCloudMachines of the cluster are being deleted,
+	// the cluster infrastructure is only deleted once they are gone.
+	WaitingForMachinesDeletionReason = "WaitingForMachinesDeletion"
+	// DependencyInUseReason used when a resource cannot be deleted yet because it is still in use.
+	DependencyInUseReason = "DependencyInUse"
 )
 
 const (
@@ -23,6 +23,9 @@ import (
 
 const (
 	ClusterFinalizer = "huaweicloudcluster.infrastructure.cluster.x-k8s.io"
+
+	// DefaultAPIServerPort is the default port of the Kubernetes API server.
+	DefaultAPIServerPort = 6443
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -43,10 +46,52 @@ type HuaweiCloudClusterSpec struct {
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint"`
 
+	// APIServerAllowedCIDRs is the list of CIDR blocks allowed to access the Kubernetes API server.
+	// Both IPv4 and IPv6 CIDR blocks are accepted. Defaults to any address when empty.
+	// +optional
+	APIServerAllowedCIDRs []string `json:"apiServerAllowedCIDRs,omitempty"`
+
+	// SSH configures SSH access to the cluster machines.
+	// +optional
+	SSH *SSHSpec `json:"ssh,omitempty"`
+
+	// AdditionalIngressRules are extra ingress rules added to the security group of the given role.
+	// +optional
+	AdditionalIngressRules map[SecurityGroupRole]IngressRules `json:"additionalIngressRules,omitempty"`
+
+	// ControlPlaneLoadBalancer configures the load balancer in front of the Kubernetes API servers.
+	// +optional
+	ControlPlaneLoadBalancer *HuaweiCloudLoadBalancerSpec `json:"controlPlaneLoadBalancer,omitempty"`
+
+	// SecondaryControlPlaneLoadBalancer configures an additional internal load balancer in front of
+	// the Kubernetes API servers, used by the nodes and in-cluster components to reach the API servers
+	// within the VPC. The scheme of the secondary load balancer is always internal and the primary
+	// load balancer must be internet-facing.
+	// +kubebuilder:validation:XValidation:rule="!has(self.scheme) || self.scheme == 'internal'",message="the scheme of the secondary control plane load balancer must be internal"
+	// +optional
+	SecondaryControlPlaneLoadBalancer *HuaweiCloudLoadBalancerSpec `json:"secondaryControlPlaneLoadBalancer,omitempty"`
+
+	// IdentityRef references the identity whose credentials are used to manage the cloud resources
+	// of the cluster. Defaults to the credentials of the controller.
+	// +optional
+	IdentityRef *HuaweiCloudIdentityReference `json:"identityRef,omitempty"`
+
 	// TODO, Network related fields need to be defined in the future
 	// other fields may like S3, SSHKey, etc.
 }
 
+// SSHSpec configures SSH access to the cluster machines.
+type SSHSpec struct {
+	// Disabled removes the SSH ingress rule from the cluster security groups.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// AllowedCIDRs is the list of CIDR blocks allowed to access the machines over SSH.
+	// Both IPv4 and IPv6 CIDR blocks are accepted. Defaults to any address when empty.
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+}
+
 // HuaweiCloudClusterStatus defines the observed state of HuaweiCloudCluster.
 type HuaweiCloudClusterStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -56,6 +101,11 @@ type HuaweiCloudClusterStatus struct {
 	Ready      bool                 `json:"ready"`
 	Network    NetworkStatus        `json:"networkStatus,omitempty"`
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// InternalControlPlaneEndpoint is the endpoint of the secondary internal control plane load balancer,
+	// set when a secondary control plane load balancer is configured.
+	// +optional
+	InternalControlPlaneEndpoint clusterv1.APIEndpoint `json:"internalControlPlaneEndpoint,omitempty"`
 }
 
 // +kubebuilder:object:root=true
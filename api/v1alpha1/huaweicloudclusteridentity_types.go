@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IdentitySecretAccessKey is the key of the access key ID in the Secret of a HuaweiCloudClusterStaticIdentity.
+	IdentitySecretAccessKey = "accessKey"
+
+	// IdentitySecretSecretKey is the key of the secret access key in the Secret of a HuaweiCloudClusterStaticIdentity.
+	IdentitySecretSecretKey = "secretKey"
+
+	// IdentitySecretProjectID is the optional key of the project ID in the Secret of a HuaweiCloudClusterStaticIdentity.
+	// The project of the cluster region is looked up when it is not set.
+	IdentitySecretProjectID = "projectID"
+)
+
+// HuaweiCloudIdentityKind is the kind of a cluster identity.
+type HuaweiCloudIdentityKind string
+
+const (
+	// ClusterStaticIdentityKind is the kind of a HuaweiCloudClusterStaticIdentity.
+	ClusterStaticIdentityKind = HuaweiCloudIdentityKind("HuaweiCloudClusterStaticIdentity")
+
+	// ClusterAgencyIdentityKind is the kind of a HuaweiCloudClusterAgencyIdentity.
+	ClusterAgencyIdentityKind = HuaweiCloudIdentityKind("HuaweiCloudClusterAgencyIdentity")
+)
+
+// HuaweiCloudIdentityReference references the identity whose credentials are used to manage the cloud resources.
+type HuaweiCloudIdentityReference struct {
+	// Name of the identity.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Kind of the identity.
+	// +kubebuilder:validation:Enum=HuaweiCloudClusterStaticIdentity;HuaweiCloudClusterAgencyIdentity
+	Kind HuaweiCloudIdentityKind `json:"kind"`
+}
+
+// AllowedNamespaces selects the namespaces of the HuaweiCloudClusters allowed to use an identity.
+type AllowedNamespaces struct {
+	// NamespaceList is the list of the namespaces allowed to use the identity.
+	// +optional
+	// +listType=set
+	NamespaceList []string `json:"list,omitempty"`
+
+	// Selector selects the namespaces allowed to use the identity by their labels.
+	// +optional
+	Selector metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// HuaweiCloudClusterIdentitySpec defines the fields common to all the cluster identities.
+type HuaweiCloudClusterIdentitySpec struct {
+	// AllowedNamespaces restricts the namespaces of the HuaweiCloudClusters which can use the identity.
+	// When unset, no namespace is allowed. When empty ({}), all the namespaces are allowed.
+	// +optional
+	AllowedNamespaces *AllowedNamespaces `json:"allowedNamespaces,omitempty"`
+}
+
+// HuaweiCloudClusterStaticIdentitySpec defines the desired state of HuaweiCloudClusterStaticIdentity.
+type HuaweiCloudClusterStaticIdentitySpec struct {
+	HuaweiCloudClusterIdentitySpec `json:",inline"`
+
+	// SecretRef is the name of the Secret holding the access key, the secret key and optionally
+	// the project ID of the account. The Secret must be in the namespace of the controller.
+	// +kubebuilder:validation:MinLength=1
+	SecretRef string `json:"secretRef"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=huaweicloudclusterstaticidentities,scope=Cluster,categories=cluster-api
+
+// HuaweiCloudClusterStaticIdentity provides the AK/SK of a HuaweiCloud account stored in a Secret.
+type HuaweiCloudClusterStaticIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HuaweiCloudClusterStaticIdentitySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HuaweiCloudClusterStaticIdentityList contains a list of HuaweiCloudClusterStaticIdentity.
+type HuaweiCloudClusterStaticIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HuaweiCloudClusterStaticIdentity `json:"items"`
+}
+
+// HuaweiCloudClusterAgencyIdentitySpec defines the desired state of HuaweiCloudClusterAgencyIdentity.
+// +kubebuilder:validation:XValidation:rule="has(self.domainID) || has(self.domainName)",message="one of domainID or domainName is required"
+type HuaweiCloudClusterAgencyIdentitySpec struct {
+	HuaweiCloudClusterIdentitySpec `json:",inline"`
+
+	// AgencyName is the name of the IAM agency created by the delegating account.
+	// +kubebuilder:validation:MinLength=1
+	AgencyName string `json:"agencyName"`
+
+	// DomainID is the ID of the delegating account.
+	// +optional
+	DomainID string `json:"domainID,omitempty"`
+
+	// DomainName is the name of the delegating account.
+	// +optional
+	DomainName string `json:"domainName,omitempty"`
+
+	// ProjectID is the project of the delegating account in the cluster region.
+	// The project of the cluster region is looked up when it is not set.
+	// +optional
+	ProjectID string `json:"projectID,omitempty"`
+
+	// DurationSeconds is the validity of the temporary credentials obtained by assuming the agency.
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=900
+	// +kubebuilder:validation:Maximum=86400
+	// +optional
+	DurationSeconds int32 `json:"durationSeconds,omitempty"`
+
+	// SourceIdentityRef is the HuaweiCloudClusterStaticIdentity used to assume the agency.
+	// Defaults to the credentials of the controller.
+	// +optional
+	SourceIdentityRef *HuaweiCloudIdentityReference `json:"sourceIdentityRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=huaweicloudclusteragencyidentities,scope=Cluster,categories=cluster-api
+
+// HuaweiCloudClusterAgencyIdentity provides the temporary credentials obtained by assuming an IAM agency
+// of another HuaweiCloud account.
+type HuaweiCloudClusterAgencyIdentity struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HuaweiCloudClusterAgencyIdentitySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HuaweiCloudClusterAgencyIdentityList contains a list of HuaweiCloudClusterAgencyIdentity.
+type HuaweiCloudClusterAgencyIdentityList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HuaweiCloudClusterAgencyIdentity `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(
+		&HuaweiCloudClusterStaticIdentity{}, &HuaweiCloudClusterStaticIdentityList{},
+		&HuaweiCloudClusterAgencyIdentity{}, &HuaweiCloudClusterAgencyIdentityList{},
+	)
+}
@@ -104,6 +104,10 @@ type HuaweiCloudMachineStatus struct {
 	// +optional
 	InstanceState *InstanceState `json:"instanceState,omitempty"`
 
+	// DeleteJobID is the ID of the ECS job deleting the instance with its volumes and EIPs.
+	// +optional
+	DeleteJobID string `json:"deleteJobId,omitempty"`
+
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
 
@@ -30,6 +30,113 @@ type NetworkSpec struct {
 	// Subnets configuration.
 	// +optional
 	Subnets Subnets `json:"subnets,omitempty"`
+
+	// CNI configuration.
+	// +optional
+	CNI *CNISpec `json:"cni,omitempty"`
+
+	// NatGateway configures the NAT gateways providing outbound access to the subnets.
+	// +optional
+	NatGateway *NatGatewaySpec `json:"natGateway,omitempty"`
+}
+
+// NatGatewaySize is the specification of a NAT gateway, it defines the maximum number of SNAT connections.
+type NatGatewaySize string
+
+const (
+	// NatGatewaySizeSmall supports up to 10,000 SNAT connections.
+	NatGatewaySizeSmall = NatGatewaySize("Small")
+	// NatGatewaySizeMedium supports up to 50,000 SNAT connections.
+	NatGatewaySizeMedium = NatGatewaySize("Medium")
+	// NatGatewaySizeLarge supports up to 200,000 SNAT connections.
+	NatGatewaySizeLarge = NatGatewaySize("Large")
+	// NatGatewaySizeExtraLarge supports up to 1,000,000 SNAT connections.
+	NatGatewaySizeExtraLarge = NatGatewaySize("ExtraLarge")
+)
+
+// NatGatewayPlacement defines how many NAT gateways are created for the subnets.
+type NatGatewayPlacement string
+
+const (
+	// NatGatewayPlacementSingle creates a single NAT gateway shared by all the subnets.
+	NatGatewayPlacementSingle = NatGatewayPlacement("Single")
+	// NatGatewayPlacementPerAvailabilityZone creates a NAT gateway for the subnets of each availability zone.
+	NatGatewayPlacementPerAvailabilityZone = NatGatewayPlacement("PerAvailabilityZone")
+)
+
+// EIPChargeMode defines how the bandwidth of an EIP is billed.
+type EIPChargeMode string
+
+const (
+	// EIPChargeModeTraffic bills the bandwidth by traffic.
+	EIPChargeModeTraffic = EIPChargeMode("traffic")
+	// EIPChargeModeBandwidth bills the bandwidth by size.
+	EIPChargeModeBandwidth = EIPChargeMode("bandwidth")
+)
+
+// NatGatewaySpec configures the NAT gateways of the cluster.
+type NatGatewaySpec struct {
+	// Size is the specification of the NAT gateways.
+	// +kubebuilder:validation:Enum=Small;Medium;Large;ExtraLarge
+	// +kubebuilder:default=Small
+	// +optional
+	Size NatGatewaySize `json:"size,omitempty"`
+
+	// Placement defines whether a single NAT gateway is shared by all the subnets
+	// or a NAT gateway is created per availability zone.
+	// +kubebuilder:validation:Enum=Single;PerAvailabilityZone
+	// +kubebuilder:default=PerAvailabilityZone
+	// +optional
+	Placement NatGatewayPlacement `json:"placement,omitempty"`
+
+	// EIPIDs are the IDs of existing EIPs to use for the SNAT rules of the NAT gateways, in order.
+	// A new EIP is allocated for each NAT gateway without an EIP from this list.
+	// The EIPs listed here are not released when the cluster is deleted.
+	// +optional
+	EIPIDs []string `json:"eipIds,omitempty"`
+
+	// Bandwidth configures the bandwidth of the EIPs allocated for the SNAT rules.
+	// +optional
+	Bandwidth *BandwidthSpec `json:"bandwidth,omitempty"`
+}
+
+// BandwidthSpec configures the bandwidth of an EIP.
+type BandwidthSpec struct {
+	// Size is the bandwidth size in Mbit/s.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	// +optional
+	Size int32 `json:"size,omitempty"`
+
+	// ChargeMode defines how the bandwidth is billed.
+	// +kubebuilder:validation:Enum=traffic;bandwidth
+	// +kubebuilder:default=traffic
+	// +optional
+	ChargeMode EIPChargeMode `json:"chargeMode,omitempty"`
+}
+
+// CNISpec defines configuration for CNI.
+type CNISpec struct {
+	// CNIIngressRules specify rules to apply to control plane and worker node security groups.
+	// The source for the rule will be set to control plane and worker security group IDs.
+	// When empty, the rules required by Calico (BGP, IP-in-IP and VXLAN) are applied.
+	// +optional
+	CNIIngressRules CNIIngressRules `json:"cniIngressRules,omitempty"`
+}
+
+// CNIIngressRules is a slice of CNIIngressRule.
+type CNIIngressRules []CNIIngressRule
+
+// CNIIngressRule defines an HuaweiCloud ingress rule for CNI requirements.
+type CNIIngressRule struct {
+	// Description provides extended information about the ingress rule.
+	Description string `json:"description"`
+	// Protocol is the protocol for the ingress rule.
+	Protocol SecurityGroupProtocol `json:"protocol"`
+	// PortRangeMin is the start of port range.
+	PortRangeMin int64 `json:"portRangeMin"`
+	// PortRangeMax is the end of port range.
+	PortRangeMax int64 `json:"portRangeMax"`
 }
 
 type VPCSpec struct {
@@ -41,6 +148,11 @@ type VPCSpec struct {
 
 	// Cidr is the CIDR of the VPC.
 	Cidr string `json:"cidr"`
+
+	// IPv6Enabled enables IPv6 on the subnets of the VPC so that dual-stack clusters can be provisioned.
+	// HuaweiCloud assigns the IPv6 CIDR block of each subnet, it is reported in the subnet IPv6CidrBlock field.
+	// +optional
+	IPv6Enabled bool `json:"ipv6Enabled,omitempty"`
 }
 
 // SubnetSpec configures an HuaweiCloud VPC Subnet.
@@ -71,12 +183,16 @@ type SubnetSpec struct {
 	// NeutronSubnetId is the identifier of the subnet (OpenStack Neutron interface).
 	NeutronSubnetId string `json:"neutron_subnet_id"`
 
-	// IPv6CidrBlock is the IPv6 CIDR block to be used when the provider creates a managed VPC.
+	// IPv6CidrBlock is the IPv6 CIDR block assigned to the subnet by HuaweiCloud, READ ONLY.
 	// A subnet can have an IPv4 and an IPv6 address.
-	// IPv6 is only supported in managed clusters, this field cannot be set on HuaweiCloudCluster object.
 	// +optional
 	IPv6CidrBlock string `json:"ipv6CidrBlock,omitempty"`
 
+	// NeutronSubnetIdV6 is the identifier of the IPv6 subnet (OpenStack Neutron interface), READ ONLY.
+	// It is set when IPv6 is enabled on the subnet.
+	// +optional
+	NeutronSubnetIdV6 string `json:"neutron_subnet_id_v6,omitempty"`
+
 	// AvailabilityZone defines the availability zone to use for this subnet in the cluster's region.
 	AvailabilityZone string `json:"availabilityZone,omitempty"`
 
@@ -85,7 +201,6 @@ type SubnetSpec struct {
 	IsPublic bool `json:"isPublic"`
 
 	// IsIPv6 defines the subnet as an IPv6 subnet. A subnet is IPv6 when it is associated with a VPC that has IPv6 enabled.
-	// IPv6 is only supported in managed clusters, this field cannot be set on HuaweiCloudCluster object.
 	// +optional
 	IsIPv6 bool `json:"isIpv6,omitempty"`
 }
@@ -117,6 +232,16 @@ func (s Subnets) FindByID(id string) *SubnetSpec {
 	return nil
 }
 
+// FilterIPv6 returns a slice containing all subnets with IPv6 enabled.
+func (s Subnets) FilterIPv6() (res Subnets) {
+	for _, x := range s {
+		if x.IsIPv6 {
+			res = append(res, x)
+		}
+	}
+	return
+}
+
 // FilterPrivate returns a slice containing all subnets marked as private.
 func (s Subnets) FilterPrivate() (res Subnets) {
 	for _, x := range s {
@@ -183,6 +308,8 @@ type IngressRule struct {
 	SourceSecurityGroupRoles []SecurityGroupRole `json:"sourceSecurityGroupRoles,omitempty"`
 
 	// NatGatewaysIPsSource use the NAT gateways IPs as the source for the ingress rule.
+	// The rule is expanded into a /32 CIDR block per IP in Status.Network.NatGatewaysIPs
+	// and follows the IPs when they change.
 	// +optional
 	NatGatewaysIPsSource bool `json:"natGatewaysIPsSource,omitempty"`
 }
@@ -342,11 +469,205 @@ type SecurityGroupRule struct {
 type PoolRef struct {
 	// Id is the unique identifier of the pool.
 	Id string `json:"id"`
+
+	// Port is the port of the pool members.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// HealthMonitorId is the unique identifier of the health monitor of the pool.
+	// +optional
+	HealthMonitorId string `json:"healthMonitorId,omitempty"`
+
+	// Members is the health of the backend servers of the pool.
+	// +optional
+	Members []PoolMember `json:"members,omitempty"`
+}
+
+// PoolMember describes a backend server of a load balancer pool.
+type PoolMember struct {
+	// Id is the unique identifier of the member.
+	Id string `json:"id"`
+
+	// Address is the IP address of the member.
+	Address string `json:"address"`
+
+	// OperatingStatus is the health of the member reported by the health monitor,
+	// e.g. ONLINE, OFFLINE or NO_MONITOR.
+	OperatingStatus string `json:"operatingStatus"`
 }
 
 type ListenerRef struct {
 	// Id is the unique identifier of the listener.
 	Id string `json:"id"`
+
+	// Port is the port of the listener.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// PoolId is the unique identifier of the default pool of the listener.
+	// +optional
+	PoolId string `json:"poolId,omitempty"`
+}
+
+// ELBScheme defines the scheme of a load balancer.
+type ELBScheme string
+
+const (
+	// ELBSchemeInternetFacing defines an internet-facing load balancer, reachable through an EIP.
+	ELBSchemeInternetFacing = ELBScheme("internet-facing")
+	// ELBSchemeInternal defines an internal load balancer, only reachable through its private VIP.
+	ELBSchemeInternal = ELBScheme("internal")
+)
+
+// HuaweiCloudLoadBalancerSpec configures an HuaweiCloud Elastic Load Balancer.
+type HuaweiCloudLoadBalancerSpec struct {
+	// Disabled skips the reconciliation of the load balancer. The control plane endpoint is managed
+	// outside of the provider, e.g. by kube-vip or an external DNS name, and must be set in
+	// HuaweiCloudClusterSpec.ControlPlaneEndpoint.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// ID is the ID of an existing load balancer to use for the control plane. Only the listeners
+	// and pools of the cluster are added to it, and it is not deleted with the cluster.
+	// The settings configuring the creation of the load balancer are ignored.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Scheme sets the scheme of the load balancer. An internal load balancer has no EIP
+	// and the control plane endpoint is its private VIP address.
+	// Defaults to internet-facing, except for the secondary load balancer which is always internal.
+	// +kubebuilder:validation:Enum=internet-facing;internal
+	// +optional
+	Scheme ELBScheme `json:"scheme,omitempty"`
+
+	// SubnetID is the ID of the cluster subnet the VIP of the load balancer is allocated in.
+	// Defaults to the first subnet of the cluster.
+	// +optional
+	SubnetID string `json:"subnetId,omitempty"`
+
+	// Port is the port of the API server listener of the load balancer and of the control plane endpoint.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=6443
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// BackendPort is the port the API servers listen on.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +kubebuilder:default=6443
+	// +optional
+	BackendPort int32 `json:"backendPort,omitempty"`
+
+	// HealthCheck configures the health monitor of the API server pool.
+	// +optional
+	HealthCheck *ELBHealthCheckSpec `json:"healthCheck,omitempty"`
+
+	// L4FlavorID is the ID of the layer 4 flavor of the load balancer, it defines the load balancer capacity.
+	// Defaults to the flavor selected by HuaweiCloud.
+	// +optional
+	L4FlavorID string `json:"l4FlavorId,omitempty"`
+
+	// AvailabilityZones is the list of availability zones the load balancer is deployed in.
+	// The zones must be usable together according to the ELB service.
+	// Defaults to the first two available zones.
+	// +optional
+	AvailabilityZones []string `json:"availabilityZones,omitempty"`
+
+	// EIP configures the EIP of an internet-facing load balancer.
+	// +optional
+	EIP *ELBEIPSpec `json:"eip,omitempty"`
+
+	// AllowedCIDRs is the list of CIDR blocks allowed to access the listeners of the load balancer.
+	// The CIDRs are enforced with an IP address group whitelist on the listeners, the VPC CIDR and the
+	// NAT gateways IPs are always allowed so that the machines of the cluster can reach the API servers.
+	// Access is not restricted when empty.
+	// +optional
+	AllowedCIDRs []string `json:"allowedCIDRs,omitempty"`
+
+	// AdditionalListeners are extra listeners of the load balancer forwarding to the control plane machines,
+	// e.g. for konnectivity. Each listener has its own pool.
+	// +listType=map
+	// +listMapKey=port
+	// +optional
+	AdditionalListeners []AdditionalListenerSpec `json:"additionalListeners,omitempty"`
+}
+
+// ELBEIPSpec configures the EIP of a load balancer.
+type ELBEIPSpec struct {
+	// ID is the ID of a pre-allocated EIP to bind to the load balancer, so the control plane endpoint
+	// stays stable when the cluster is re-created. The EIP is not released with the cluster.
+	// The other settings are ignored when it is set.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// Type is the type of the EIP, e.g. 5_bgp for dynamic BGP or 5_sbgp for static BGP.
+	// +kubebuilder:default="5_bgp"
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Bandwidth configures the dedicated bandwidth of the EIP.
+	// Ignored when SharedBandwidthID is set.
+	// +optional
+	Bandwidth *BandwidthSpec `json:"bandwidth,omitempty"`
+
+	// SharedBandwidthID is the ID of an existing shared bandwidth the EIP is added to.
+	// +optional
+	SharedBandwidthID string `json:"sharedBandwidthId,omitempty"`
+}
+
+// AdditionalListenerSpec defines an additional listener of a load balancer.
+type AdditionalListenerSpec struct {
+	// Port is the port of the listener, the control plane machines are expected to listen on the same port.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// HealthCheck configures the health monitor of the listener pool.
+	// +optional
+	HealthCheck *ELBHealthCheckSpec `json:"healthCheck,omitempty"`
+}
+
+// ELBHealthCheckProtocol defines the protocol of a load balancer health check.
+type ELBHealthCheckProtocol string
+
+const (
+	// ELBHealthCheckProtocolTCP checks that a TCP connection can be established with the backend server.
+	ELBHealthCheckProtocolTCP = ELBHealthCheckProtocol("TCP")
+	// ELBHealthCheckProtocolHTTPS checks that the backend server answers to a HTTPS request on /readyz,
+	// the readiness endpoint of the API server.
+	ELBHealthCheckProtocolHTTPS = ELBHealthCheckProtocol("HTTPS")
+)
+
+// ELBHealthCheckSpec configures the health monitor of a load balancer pool.
+type ELBHealthCheckSpec struct {
+	// Protocol is the protocol of the health check.
+	// +kubebuilder:validation:Enum=TCP;HTTPS
+	// +kubebuilder:default=TCP
+	// +optional
+	Protocol ELBHealthCheckProtocol `json:"protocol,omitempty"`
+
+	// IntervalSeconds is the interval between two health checks.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	// +kubebuilder:default=5
+	// +optional
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// TimeoutSeconds is the maximum time to wait for a health check response.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=50
+	// +kubebuilder:default=3
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// MaxRetries is the number of consecutive health checks a backend server has to pass or fail
+	// before its health changes.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +kubebuilder:default=3
+	// +optional
+	MaxRetries int32 `json:"maxRetries,omitempty"`
 }
 
 // LoadBalancer defines an AWS load balancer.
@@ -357,11 +678,29 @@ type LoadBalancer struct {
 	// Name is the name of the load balancer.
 	Name string `json:"name"`
 
+	// VipAddress is the private IPv4 virtual IP address of the load balancer.
+	// +optional
+	VipAddress string `json:"vipAddress,omitempty"`
+
+	// IPv6VipAddress is the IPv6 virtual IP address of the load balancer, if IPv6 is enabled.
+	// +optional
+	IPv6VipAddress string `json:"ipv6VipAddress,omitempty"`
+
 	// Pools is a list of pool references associated with the load balancer.
 	Pools []PoolRef `json:"pools"`
 
 	// Listeners is a list of listener references associated with the load balancer.
 	Listeners []ListenerRef `json:"listeners"`
+
+	// IPGroupId is the ID of the IP address group restricting access to the listeners.
+	// +optional
+	IPGroupId string `json:"ipGroupId,omitempty"`
+
+	// EIPIds are the IDs of the EIPs owned by the cluster which were bound to the load balancer.
+	// They are recorded when the load balancer is deleted, so that they are released even if the
+	// deletion is resumed after the load balancer is gone.
+	// +optional
+	EIPIds []string `json:"eipIds,omitempty"`
 }
 
 // SecurityGroup defines an HuaweiCloud security group.
@@ -390,6 +729,30 @@ type NetworkStatus struct {
 	// ELB is the Elastic Load Balancer associated with the cluster.
 	ELB LoadBalancer `json:"elb,omitempty"`
 
+	// SecondaryELB is the secondary internal Elastic Load Balancer associated with the cluster.
+	SecondaryELB LoadBalancer `json:"secondaryElb,omitempty"`
+
 	// NatGatewaysIPs contains the public IPs of the NAT Gateways
 	NatGatewaysIPs []string `json:"natGatewaysIPs,omitempty"`
+
+	// NatGateways contains the NAT gateways of the cluster.
+	// +optional
+	NatGateways []NatGateway `json:"natGateways,omitempty"`
+}
+
+// NatGateway describes a NAT gateway of the cluster.
+type NatGateway struct {
+	// Id is the unique identifier of the NAT gateway.
+	Id string `json:"id"`
+
+	// SubnetId is the identifier of the subnet the NAT gateway is located in.
+	SubnetId string `json:"subnetId"`
+
+	// EIPId is the identifier of the EIP used by the SNAT rules of the NAT gateway.
+	// +optional
+	EIPId string `json:"eipId,omitempty"`
+
+	// EIPAddress is the public IP address used by the SNAT rules of the NAT gateway.
+	// +optional
+	EIPAddress string `json:"eipAddress,omitempty"`
 }
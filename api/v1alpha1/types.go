@@ -128,6 +128,9 @@ type Instance struct {
 	// The public IPv4 address assigned to the instance, if applicable.
 	PublicIP *string `json:"publicIp,omitempty"`
 
+	// The IPv6 address assigned to the instance, if applicable.
+	IPv6Address *string `json:"ipv6Address,omitempty"`
+
 	// Configuration options for the root storage volume.
 	// +optional
 	RootVolume *Volume `json:"rootVolume,omitempty"`
@@ -26,6 +26,151 @@ import (
 	"sigs.k8s.io/cluster-api/errors"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdditionalListenerSpec) DeepCopyInto(out *AdditionalListenerSpec) {
+	*out = *in
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(ELBHealthCheckSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdditionalListenerSpec.
+func (in *AdditionalListenerSpec) DeepCopy() *AdditionalListenerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AdditionalListenerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AllowedNamespaces) DeepCopyInto(out *AllowedNamespaces) {
+	*out = *in
+	if in.NamespaceList != nil {
+		in, out := &in.NamespaceList, &out.NamespaceList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AllowedNamespaces.
+func (in *AllowedNamespaces) DeepCopy() *AllowedNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(AllowedNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BandwidthSpec) DeepCopyInto(out *BandwidthSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BandwidthSpec.
+func (in *BandwidthSpec) DeepCopy() *BandwidthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BandwidthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNIIngressRule) DeepCopyInto(out *CNIIngressRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNIIngressRule.
+func (in *CNIIngressRule) DeepCopy() *CNIIngressRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CNIIngressRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in CNIIngressRules) DeepCopyInto(out *CNIIngressRules) {
+	{
+		in := &in
+		*out = make(CNIIngressRules, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNIIngressRules.
+func (in CNIIngressRules) DeepCopy() CNIIngressRules {
+	if in == nil {
+		return nil
+	}
+	out := new(CNIIngressRules)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CNISpec) DeepCopyInto(out *CNISpec) {
+	*out = *in
+	if in.CNIIngressRules != nil {
+		in, out := &in.CNIIngressRules, &out.CNIIngressRules
+		*out = make(CNIIngressRules, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CNISpec.
+func (in *CNISpec) DeepCopy() *CNISpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CNISpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ELBEIPSpec) DeepCopyInto(out *ELBEIPSpec) {
+	*out = *in
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(BandwidthSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ELBEIPSpec.
+func (in *ELBEIPSpec) DeepCopy() *ELBEIPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ELBEIPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ELBHealthCheckSpec) DeepCopyInto(out *ELBHealthCheckSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ELBHealthCheckSpec.
+func (in *ELBHealthCheckSpec) DeepCopy() *ELBHealthCheckSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ELBHealthCheckSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticIPPool) DeepCopyInto(out *ElasticIPPool) {
 	*out = *in
@@ -73,6 +218,105 @@ func (in *HuaweiCloudCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterAgencyIdentity) DeepCopyInto(out *HuaweiCloudClusterAgencyIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterAgencyIdentity.
+func (in *HuaweiCloudClusterAgencyIdentity) DeepCopy() *HuaweiCloudClusterAgencyIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterAgencyIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HuaweiCloudClusterAgencyIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterAgencyIdentityList) DeepCopyInto(out *HuaweiCloudClusterAgencyIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HuaweiCloudClusterAgencyIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterAgencyIdentityList.
+func (in *HuaweiCloudClusterAgencyIdentityList) DeepCopy() *HuaweiCloudClusterAgencyIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterAgencyIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HuaweiCloudClusterAgencyIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterAgencyIdentitySpec) DeepCopyInto(out *HuaweiCloudClusterAgencyIdentitySpec) {
+	*out = *in
+	in.HuaweiCloudClusterIdentitySpec.DeepCopyInto(&out.HuaweiCloudClusterIdentitySpec)
+	if in.SourceIdentityRef != nil {
+		in, out := &in.SourceIdentityRef, &out.SourceIdentityRef
+		*out = new(HuaweiCloudIdentityReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterAgencyIdentitySpec.
+func (in *HuaweiCloudClusterAgencyIdentitySpec) DeepCopy() *HuaweiCloudClusterAgencyIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterAgencyIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterIdentitySpec) DeepCopyInto(out *HuaweiCloudClusterIdentitySpec) {
+	*out = *in
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = new(AllowedNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterIdentitySpec.
+func (in *HuaweiCloudClusterIdentitySpec) DeepCopy() *HuaweiCloudClusterIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HuaweiCloudClusterList) DeepCopyInto(out *HuaweiCloudClusterList) {
 	*out = *in
@@ -110,6 +354,49 @@ func (in *HuaweiCloudClusterSpec) DeepCopyInto(out *HuaweiCloudClusterSpec) {
 	*out = *in
 	in.NetworkSpec.DeepCopyInto(&out.NetworkSpec)
 	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	if in.APIServerAllowedCIDRs != nil {
+		in, out := &in.APIServerAllowedCIDRs, &out.APIServerAllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(SSHSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalIngressRules != nil {
+		in, out := &in.AdditionalIngressRules, &out.AdditionalIngressRules
+		*out = make(map[SecurityGroupRole]IngressRules, len(*in))
+		for key, val := range *in {
+			var outVal []IngressRule
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make(IngressRules, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ControlPlaneLoadBalancer != nil {
+		in, out := &in.ControlPlaneLoadBalancer, &out.ControlPlaneLoadBalancer
+		*out = new(HuaweiCloudLoadBalancerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecondaryControlPlaneLoadBalancer != nil {
+		in, out := &in.SecondaryControlPlaneLoadBalancer, &out.SecondaryControlPlaneLoadBalancer
+		*out = new(HuaweiCloudLoadBalancerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IdentityRef != nil {
+		in, out := &in.IdentityRef, &out.IdentityRef
+		*out = new(HuaweiCloudIdentityReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterSpec.
@@ -122,6 +409,80 @@ func (in *HuaweiCloudClusterSpec) DeepCopy() *HuaweiCloudClusterSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterStaticIdentity) DeepCopyInto(out *HuaweiCloudClusterStaticIdentity) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterStaticIdentity.
+func (in *HuaweiCloudClusterStaticIdentity) DeepCopy() *HuaweiCloudClusterStaticIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterStaticIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HuaweiCloudClusterStaticIdentity) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterStaticIdentityList) DeepCopyInto(out *HuaweiCloudClusterStaticIdentityList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HuaweiCloudClusterStaticIdentity, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterStaticIdentityList.
+func (in *HuaweiCloudClusterStaticIdentityList) DeepCopy() *HuaweiCloudClusterStaticIdentityList {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterStaticIdentityList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HuaweiCloudClusterStaticIdentityList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudClusterStaticIdentitySpec) DeepCopyInto(out *HuaweiCloudClusterStaticIdentitySpec) {
+	*out = *in
+	in.HuaweiCloudClusterIdentitySpec.DeepCopyInto(&out.HuaweiCloudClusterIdentitySpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterStaticIdentitySpec.
+func (in *HuaweiCloudClusterStaticIdentitySpec) DeepCopy() *HuaweiCloudClusterStaticIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudClusterStaticIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HuaweiCloudClusterStatus) DeepCopyInto(out *HuaweiCloudClusterStatus) {
 	*out = *in
@@ -133,6 +494,7 @@ func (in *HuaweiCloudClusterStatus) DeepCopyInto(out *HuaweiCloudClusterStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.InternalControlPlaneEndpoint = in.InternalControlPlaneEndpoint
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudClusterStatus.
@@ -145,6 +507,63 @@ func (in *HuaweiCloudClusterStatus) DeepCopy() *HuaweiCloudClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudIdentityReference) DeepCopyInto(out *HuaweiCloudIdentityReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudIdentityReference.
+func (in *HuaweiCloudIdentityReference) DeepCopy() *HuaweiCloudIdentityReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudIdentityReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuaweiCloudLoadBalancerSpec) DeepCopyInto(out *HuaweiCloudLoadBalancerSpec) {
+	*out = *in
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(ELBHealthCheckSpec)
+		**out = **in
+	}
+	if in.AvailabilityZones != nil {
+		in, out := &in.AvailabilityZones, &out.AvailabilityZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EIP != nil {
+		in, out := &in.EIP, &out.EIP
+		*out = new(ELBEIPSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdditionalListeners != nil {
+		in, out := &in.AdditionalListeners, &out.AdditionalListeners
+		*out = make([]AdditionalListenerSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuaweiCloudLoadBalancerSpec.
+func (in *HuaweiCloudLoadBalancerSpec) DeepCopy() *HuaweiCloudLoadBalancerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HuaweiCloudLoadBalancerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HuaweiCloudMachine) DeepCopyInto(out *HuaweiCloudMachine) {
 	*out = *in
@@ -507,6 +926,11 @@ func (in *Instance) DeepCopyInto(out *Instance) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.IPv6Address != nil {
+		in, out := &in.IPv6Address, &out.IPv6Address
+		*out = new(string)
+		**out = **in
+	}
 	if in.RootVolume != nil {
 		in, out := &in.RootVolume, &out.RootVolume
 		*out = new(Volume)
@@ -557,13 +981,20 @@ func (in *LoadBalancer) DeepCopyInto(out *LoadBalancer) {
 	if in.Pools != nil {
 		in, out := &in.Pools, &out.Pools
 		*out = make([]PoolRef, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Listeners != nil {
 		in, out := &in.Listeners, &out.Listeners
 		*out = make([]ListenerRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.EIPIds != nil {
+		in, out := &in.EIPIds, &out.EIPIds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LoadBalancer.
@@ -576,6 +1007,46 @@ func (in *LoadBalancer) DeepCopy() *LoadBalancer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatGateway) DeepCopyInto(out *NatGateway) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatGateway.
+func (in *NatGateway) DeepCopy() *NatGateway {
+	if in == nil {
+		return nil
+	}
+	out := new(NatGateway)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NatGatewaySpec) DeepCopyInto(out *NatGatewaySpec) {
+	*out = *in
+	if in.EIPIDs != nil {
+		in, out := &in.EIPIDs, &out.EIPIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(BandwidthSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NatGatewaySpec.
+func (in *NatGatewaySpec) DeepCopy() *NatGatewaySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NatGatewaySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 	*out = *in
@@ -585,6 +1056,16 @@ func (in *NetworkSpec) DeepCopyInto(out *NetworkSpec) {
 		*out = make(Subnets, len(*in))
 		copy(*out, *in)
 	}
+	if in.CNI != nil {
+		in, out := &in.CNI, &out.CNI
+		*out = new(CNISpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NatGateway != nil {
+		in, out := &in.NatGateway, &out.NatGateway
+		*out = new(NatGatewaySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkSpec.
@@ -608,11 +1089,17 @@ func (in *NetworkStatus) DeepCopyInto(out *NetworkStatus) {
 		}
 	}
 	in.ELB.DeepCopyInto(&out.ELB)
+	in.SecondaryELB.DeepCopyInto(&out.SecondaryELB)
 	if in.NatGatewaysIPs != nil {
 		in, out := &in.NatGatewaysIPs, &out.NatGatewaysIPs
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NatGateways != nil {
+		in, out := &in.NatGateways, &out.NatGateways
+		*out = make([]NatGateway, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkStatus.
@@ -625,9 +1112,29 @@ func (in *NetworkStatus) DeepCopy() *NetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolMember) DeepCopyInto(out *PoolMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolMember.
+func (in *PoolMember) DeepCopy() *PoolMember {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolRef) DeepCopyInto(out *PoolRef) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PoolMember, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolRef.
@@ -640,6 +1147,26 @@ func (in *PoolRef) DeepCopy() *PoolRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHSpec) DeepCopyInto(out *SSHSpec) {
+	*out = *in
+	if in.AllowedCIDRs != nil {
+		in, out := &in.AllowedCIDRs, &out.AllowedCIDRs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHSpec.
+func (in *SSHSpec) DeepCopy() *SSHSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityGroup) DeepCopyInto(out *SecurityGroup) {
 	*out = *in
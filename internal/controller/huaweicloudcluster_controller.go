@@ -22,8 +22,11 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
 	capiannotations "sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -31,11 +34,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/credentials"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/elb"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/network"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/securitygroup"
-	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
 	"github.com/pkg/errors"
 )
 
@@ -50,7 +54,12 @@ var defaultHCSecurityGroupRoles = []infrav1alpha1.SecurityGroupRole{
 type HuaweiCloudClusterReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
-	Credentials *basic.Credentials
+	Credentials credentials.Provider
+	Recorder    record.EventRecorder
+
+	// DriftCheckInterval is the period the cluster infrastructure is checked for resources
+	// deleted or changed out of band. The check is disabled when zero.
+	DriftCheckInterval time.Duration
 }
 
 // securityGroupRolesForCluster returns the security group roles determined by the cluster configuration.
@@ -65,6 +74,9 @@ func securityGroupRolesForCluster() []infrav1alpha1.SecurityGroupRole {
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=huaweicloudclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=huaweicloudclusters/finalizers,verbs=update
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+// +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=huaweicloudclusterstaticidentities;huaweicloudclusteragencyidentities,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=secrets;namespaces,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -108,12 +120,13 @@ func (r *HuaweiCloudClusterReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}
 
 	// Create the scope.
-	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
 		Client:      r.Client,
 		Logger:      &log,
 		Cluster:     cluster,
 		HCCluster:   hcCluster,
 		Credentials: r.Credentials,
+		Recorder:    r.Recorder,
 	})
 	if err != nil {
 		return reconcile.Result{}, errors.Errorf("failed to create scope: %+v", err)
@@ -126,10 +139,10 @@ func (r *HuaweiCloudClusterReconciler) Reconcile(ctx context.Context, req ctrl.R
 	}()
 
 	if !hcCluster.DeletionTimestamp.IsZero() {
-		return ctrl.Result{}, r.reconcileDelete(clusterScope)
+		return r.reconcileDelete(ctx, clusterScope)
 	}
 
-	return r.reconcileNormal(clusterScope)
+	return r.reconcileNormal(ctx, clusterScope)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -140,7 +153,7 @@ func (r *HuaweiCloudClusterReconciler) SetupWithManager(mgr ctrl.Manager) error
 		Complete(r)
 }
 
-func (r *HuaweiCloudClusterReconciler) reconcileNormal(clusterScope *scope.ClusterScope) (reconcile.Result, error) {
+func (r *HuaweiCloudClusterReconciler) reconcileNormal(ctx context.Context, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
 	clusterScope.Logger.Info("Reconciling HuaweiCloudCluster")
 
 	hccluster := clusterScope.HCCluster
@@ -156,7 +169,7 @@ func (r *HuaweiCloudClusterReconciler) reconcileNormal(clusterScope *scope.Clust
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create network service")
 	}
-	if err := networkSvc.ReconcileNetwork(); err != nil {
+	if err := networkSvc.ReconcileNetwork(ctx); err != nil {
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, "failed to reconcile network")
 	}
 
@@ -165,7 +178,7 @@ func (r *HuaweiCloudClusterReconciler) reconcileNormal(clusterScope *scope.Clust
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create security group service")
 	}
-	if err := sgSvc.ReconcileSecurityGroups(); err != nil {
+	if err := sgSvc.ReconcileSecurityGroups(ctx); err != nil {
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, "failed to reconcile security groups")
 	}
 
@@ -174,50 +187,104 @@ func (r *HuaweiCloudClusterReconciler) reconcileNormal(clusterScope *scope.Clust
 	if err != nil {
 		return reconcile.Result{}, errors.Wrap(err, "failed to create elb service")
 	}
-	if err := elbSvc.ReconcileLoadbalancers(); err != nil {
+	if err := elbSvc.ReconcileLoadbalancers(ctx); err != nil {
 		return reconcile.Result{RequeueAfter: 30 * time.Second}, errors.Wrap(err, "failed to reconcile load balancers")
 	}
 
 	hccluster.Status.Ready = true
-	return reconcile.Result{}, nil
+
+	// requeue periodically to detect the resources deleted out of band and refresh the health of the members
+	return reconcile.Result{RequeueAfter: r.DriftCheckInterval}, nil
 }
 
-func (r *HuaweiCloudClusterReconciler) reconcileDelete(clusterScope *scope.ClusterScope) error {
+// reconcileDelete deletes the cluster infrastructure once the HuaweiCloudMachines of the cluster are gone,
+// their ports would otherwise keep the subnets and security groups in use. The load balancers are deleted
+// first, then the security groups and the network.
+func (r *HuaweiCloudClusterReconciler) reconcileDelete(ctx context.Context, clusterScope *scope.ClusterScope) (reconcile.Result, error) {
 	// Reconcile network
 	if !controllerutil.ContainsFinalizer(clusterScope.HCCluster, infrav1alpha1.ClusterFinalizer) {
 		clusterScope.Logger.Info("No finalizer on HuaweiCloudCluster, skipping deletion reconciliation")
-		return nil
+		return reconcile.Result{}, nil
 	}
 
 	clusterScope.Logger.Info("Deleting HuaweiCloudCluster")
 
-	// delete load balancer
-	elbSvc, err := elb.NewService(clusterScope)
-	if err != nil {
-		return errors.Wrap(err, "failed to create elb service")
-	}
-	if err := elbSvc.DeleteLoadbalancers(); err != nil {
-		return errors.Wrap(err, "failed to delete load balancers")
-	}
-
-	// delete security group
-	sgSvc, err := securitygroup.NewService(clusterScope, securityGroupRolesForCluster())
+	machines, err := r.listHuaweiCloudMachines(ctx, clusterScope)
 	if err != nil {
-		return errors.Wrap(err, "failed to create security group service")
+		return reconcile.Result{}, err
 	}
-	if err := sgSvc.DeleteSecurityGroups(); err != nil {
-		return errors.Wrap(err, "failed to delete security groups")
+	if len(machines) > 0 {
+		clusterScope.Logger.Info("Waiting for HuaweiCloudMachines to be deleted", "count", len(machines))
+		conditions.MarkFalse(clusterScope.HCCluster, infrav1alpha1.InfrastructureDeletedCondition,
+			infrav1alpha1.WaitingForMachinesDeletionReason, clusterv1.ConditionSeverityInfo,
+			"waiting for %d HuaweiCloudMachines to be deleted", len(machines))
+		return reconcile.Result{RequeueAfter: 15 * time.Second}, nil
 	}
 
-	// delete network
-	networkSvc, err := network.NewService(clusterScope)
-	if err != nil {
-		return errors.Wrap(err, "failed to create network service")
+	steps := []struct {
+		name   string
+		delete func() error
+	}{
+		{
+			name: "load balancers",
+			delete: func() error {
+				elbSvc, err := elb.NewService(clusterScope)
+				if err != nil {
+					return errors.Wrap(err, "failed to create elb service")
+				}
+				return elbSvc.DeleteLoadbalancers(ctx)
+			},
+		},
+		{
+			name: "security groups",
+			delete: func() error {
+				sgSvc, err := securitygroup.NewService(clusterScope, securityGroupRolesForCluster())
+				if err != nil {
+					return errors.Wrap(err, "failed to create security group service")
+				}
+				return sgSvc.DeleteSecurityGroups(ctx)
+			},
+		},
+		{
+			name: "network",
+			delete: func() error {
+				networkSvc, err := network.NewService(clusterScope)
+				if err != nil {
+					return errors.Wrap(err, "failed to create network service")
+				}
+				return networkSvc.DeleteNetwork(ctx)
+			},
+		},
 	}
-	if err := networkSvc.DeleteNetwork(); err != nil {
-		return errors.Wrap(err, "failed to delete network")
+	for _, step := range steps {
+		conditions.MarkFalse(clusterScope.HCCluster, infrav1alpha1.InfrastructureDeletedCondition,
+			clusterv1.DeletingReason, clusterv1.ConditionSeverityInfo, "deleting %s", step.name)
+		if err := step.delete(); err != nil {
+			// a resource still used by another one, e.g. a port being released, is retried later
+			if ecserrors.IsDependencyInUse(err) {
+				clusterScope.Logger.Info("Resources are still in use, requeuing", "step", step.name, "reason", err.Error())
+				conditions.MarkFalse(clusterScope.HCCluster, infrav1alpha1.InfrastructureDeletedCondition,
+					infrav1alpha1.DependencyInUseReason, clusterv1.ConditionSeverityWarning,
+					"%s are still in use: %v", step.name, err)
+				return reconcile.Result{RequeueAfter: 30 * time.Second}, nil
+			}
+			return reconcile.Result{}, errors.Wrapf(err, "failed to delete %s", step.name)
+		}
 	}
 
+	conditions.MarkTrue(clusterScope.HCCluster, infrav1alpha1.InfrastructureDeletedCondition)
 	controllerutil.RemoveFinalizer(clusterScope.HCCluster, infrav1alpha1.ClusterFinalizer)
-	return nil
+	return reconcile.Result{}, nil
+}
+
+// listHuaweiCloudMachines returns the HuaweiCloudMachines of the cluster.
+func (r *HuaweiCloudClusterReconciler) listHuaweiCloudMachines(ctx context.Context, clusterScope *scope.ClusterScope) ([]infrav1alpha1.HuaweiCloudMachine, error) {
+	machineList := &infrav1alpha1.HuaweiCloudMachineList{}
+	if err := r.Client.List(ctx, machineList,
+		client.InNamespace(clusterScope.HCCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterScope.ClusterName()},
+	); err != nil {
+		return nil, errors.Wrap(err, "failed to list HuaweiCloudMachines")
+	}
+	return machineList.Items, nil
 }
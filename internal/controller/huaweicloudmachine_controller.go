@@ -25,24 +25,29 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
+	capiannotations "sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/predicates"
 
 	infrav1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
-	hwbasic "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/basic"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/credentials"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/ecs"
-	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/elb"
 )
 
 const (
@@ -54,7 +59,8 @@ const (
 type HuaweiCloudMachineReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
-	Credentials *basic.Credentials
+	Credentials credentials.Provider
+	Recorder    record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io,resources=huaweicloudmachines,verbs=get;list;watch;create;update;patch;delete
@@ -103,6 +109,11 @@ func (r *HuaweiCloudMachineReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	log = log.WithValues("cluster", klog.KObj(cluster))
 
+	if capiannotations.IsPaused(cluster, hcMachine) {
+		log.Info("HuaweiCloudMachine or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
 	infraCluster, err := r.getInfraCluster(ctx, &log, cluster, hcMachine)
 	if err != nil {
 		return ctrl.Result{}, errors.Errorf("error getting infra provider cluster or control plane object: %v", err)
@@ -135,7 +146,7 @@ func (r *HuaweiCloudMachineReconciler) Reconcile(ctx context.Context, req ctrl.R
 	switch infraScope := infraCluster.(type) {
 	case *scope.ClusterScope:
 		if !machine.ObjectMeta.DeletionTimestamp.IsZero() {
-			return r.reconcileDelete(machineScope, infraScope, infraScope)
+			return r.reconcileDelete(ctx, machineScope, infraScope, infraScope)
 		}
 
 		return r.reconcileNormal(ctx, machineScope, infraScope, infraScope)
@@ -160,12 +171,13 @@ func (r *HuaweiCloudMachineReconciler) getInfraCluster(ctx context.Context, log
 	}
 
 	// Create the cluster scope
-	clusterScope, err := scope.NewClusterScope(scope.ClusterScopeParams{
+	clusterScope, err := scope.NewClusterScope(ctx, scope.ClusterScopeParams{
 		Client:      r.Client,
 		Logger:      log,
 		Cluster:     cluster,
 		HCCluster:   hcCluster,
 		Credentials: r.Credentials,
+		Recorder:    r.Recorder,
 	})
 	if err != nil {
 		return nil, err
@@ -177,7 +189,7 @@ func (r *HuaweiCloudMachineReconciler) getInfraCluster(ctx context.Context, log
 // findInstance queries the ECS apis and retrieves the instance if it exists.
 // If providerID is empty, finds instance by tags and if it cannot be found, returns empty instance with nil error.
 // If providerID is set, either finds the instance by ID or returns error.
-func (r *HuaweiCloudMachineReconciler) findInstance(machineScope *scope.MachineScope, ecsSvc services.ECSInterface) (*infrav1.Instance, error) {
+func (r *HuaweiCloudMachineReconciler) findInstance(ctx context.Context, machineScope *scope.MachineScope, ecsSvc services.ECSInterface) (*infrav1.Instance, error) {
 	var instance *infrav1.Instance
 
 	// Parse the ProviderID.
@@ -197,7 +209,7 @@ func (r *HuaweiCloudMachineReconciler) findInstance(machineScope *scope.MachineS
 		// If the ProviderID is populated, describe the instance using the ID.
 		// InstanceIfExists() returns error (ErrInstanceNotFoundByID or ErrDescribeInstance) if the instance could not be found.
 		//nolint:staticcheck
-		instance, err = ecsSvc.InstanceIfExists(ptr.To[string](pid.ID()))
+		instance, err = ecsSvc.InstanceIfExists(ctx, ptr.To[string](pid.ID()))
 		if err != nil {
 			return nil, err
 		}
@@ -209,13 +221,75 @@ func (r *HuaweiCloudMachineReconciler) findInstance(machineScope *scope.MachineS
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *HuaweiCloudMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	log := ctrl.Log.WithName("huaweicloudmachine")
+
+	clusterToHuaweiCloudMachines, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.HuaweiCloudMachineList{}, mgr.GetScheme())
+	if err != nil {
+		return errors.Wrap(err, "failed to create mapper for Cluster to HuaweiCloudMachines")
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&infrav1.HuaweiCloudMachine{}).
 		Named("huaweicloudmachine").
+		WithEventFilter(predicates.ResourceNotPaused(mgr.GetScheme(), log)).
+		// the bootstrap data is set on the owning Machine
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("HuaweiCloudMachine"))),
+		).
+		Watches(
+			&infrav1.HuaweiCloudCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.HuaweiCloudClusterToHuaweiCloudMachines(log)),
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToHuaweiCloudMachines),
+			builder.WithPredicates(predicates.ClusterPausedTransitionsOrInfrastructureReady(mgr.GetScheme(), log)),
+		).
 		Complete(r)
 }
 
-func (r *HuaweiCloudMachineReconciler) reconcileDelete(machineScope *scope.MachineScope, _ hwbasic.ClusterScoper, ecsScope scope.ECSScope) (ctrl.Result, error) {
+// HuaweiCloudClusterToHuaweiCloudMachines is a handler.MapFunc to be used to enqueue requests for reconciliation
+// of HuaweiCloudMachines when the HuaweiCloudCluster changes, e.g. once its infrastructure is ready.
+func (r *HuaweiCloudMachineReconciler) HuaweiCloudClusterToHuaweiCloudMachines(log logr.Logger) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []ctrl.Request {
+		hcCluster, ok := o.(*infrav1.HuaweiCloudCluster)
+		if !ok {
+			log.Error(errors.Errorf("expected a HuaweiCloudCluster but got a %T", o), "failed to get HuaweiCloudMachines for HuaweiCloudCluster")
+			return nil
+		}
+
+		// Don't handle deleted HuaweiCloudClusters
+		if !hcCluster.ObjectMeta.DeletionTimestamp.IsZero() {
+			return nil
+		}
+
+		cluster, err := util.GetOwnerCluster(ctx, r.Client, hcCluster.ObjectMeta)
+		if err != nil || cluster == nil {
+			return nil
+		}
+
+		machineList := &clusterv1.MachineList{}
+		if err := r.Client.List(ctx, machineList, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterNameLabel: cluster.Name}); err != nil {
+			log.Error(err, "failed to list Machines", "cluster", klog.KObj(cluster))
+			return nil
+		}
+
+		requests := []ctrl.Request{}
+		for _, machine := range machineList.Items {
+			if machine.Spec.InfrastructureRef.GroupVersionKind().GroupKind() != infrav1.GroupVersion.WithKind("HuaweiCloudMachine").GroupKind() ||
+				machine.Spec.InfrastructureRef.Name == "" {
+				continue
+			}
+			requests = append(requests, ctrl.Request{
+				NamespacedName: client.ObjectKey{Namespace: machine.Namespace, Name: machine.Spec.InfrastructureRef.Name},
+			})
+		}
+		return requests
+	}
+}
+
+func (r *HuaweiCloudMachineReconciler) reconcileDelete(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, ecsScope scope.ECSScope) (ctrl.Result, error) {
 	machineScope.Logger.Info("Handling deleted HuaweiCloudMachine")
 
 	ecsSvc, err := ecs.NewService(ecsScope)
@@ -224,7 +298,7 @@ func (r *HuaweiCloudMachineReconciler) reconcileDelete(machineScope *scope.Machi
 		return ctrl.Result{}, err
 	}
 
-	instance, err := r.findInstance(machineScope, ecsSvc)
+	instance, err := r.findInstance(ctx, machineScope, ecsSvc)
 	if err != nil && err != ecs.ErrInstanceNotFoundByID {
 		machineScope.Logger.Error(err, "query to find instance failed")
 		return ctrl.Result{}, err
@@ -245,6 +319,31 @@ func (r *HuaweiCloudMachineReconciler) reconcileDelete(machineScope *scope.Machi
 
 	machineScope.Logger.Info("ECS instance found matching deleted HuaweiCloudMachine", "instance-id", instance.ID)
 
+	// The finalizer is only removed once the deletion job succeeded, so that the volumes and EIPs
+	// of the instance are released.
+	if jobId := machineScope.HCMachine.Status.DeleteJobID; jobId != "" {
+		done, err := ecsSvc.DeleteJobStatus(ctx, jobId)
+		if err != nil {
+			if errors.Is(err, ecs.ErrDeleteJobFailed) {
+				// the instance is deleted again on the next reconcile
+				machineScope.HCMachine.Status.DeleteJobID = ""
+				conditions.MarkFalse(machineScope.HCMachine, infrav1.InstanceReadyCondition, "DeletingFailed", clusterv1.ConditionSeverityWarning, "failed to terminate instance: %v", err)
+				machineScope.Warningf(err, "FailedTerminate", "Failed to terminate instance %s", instance.ID)
+			}
+			machineScope.Logger.Error(err, "failed to check deletion job", "instance-id", instance.ID, "job-id", jobId)
+			return ctrl.Result{}, err
+		}
+		if !done || instance.State != infrav1.InstanceStateTerminated {
+			machineScope.Logger.Info("Waiting for ECS instance to be terminated", "instance-id", instance.ID, "job-id", jobId)
+			return ctrl.Result{RequeueAfter: DefaultReconcilerRequeue}, nil
+		}
+		machineScope.Logger.Info("ECS instance terminated successfully", "instance-id", instance.ID)
+		machineScope.Eventf("SuccessfulTerminate", "Terminated instance %s", instance.ID)
+		conditions.MarkFalse(machineScope.HCMachine, infrav1.InstanceReadyCondition, clusterv1.DeletedReason, clusterv1.ConditionSeverityInfo, "")
+		controllerutil.RemoveFinalizer(machineScope.HCMachine, infrav1.MachineFinalizer)
+		return ctrl.Result{}, nil
+	}
+
 	// Check the instance state. If it's already shutting down or terminated,
 	// do nothing. Otherwise attempt to delete it.
 	switch instance.State {
@@ -266,20 +365,28 @@ func (r *HuaweiCloudMachineReconciler) reconcileDelete(machineScope *scope.Machi
 			return ctrl.Result{}, err
 		}
 
-		if err := ecsSvc.TerminateInstance(instance.ID); err != nil {
+		if err := r.deregisterFromLoadBalancers(ctx, machineScope, clusterScope, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		jobId, err := ecsSvc.TerminateInstance(ctx, instance.ID)
+		if err != nil {
 			machineScope.Logger.Error(err, "failed to terminate instance")
 			conditions.MarkFalse(machineScope.HCMachine, infrav1.InstanceReadyCondition, "DeletingFailed", clusterv1.ConditionSeverityWarning, "failed to terminate instance: %v", err)
+			machineScope.Warningf(err, "FailedTerminate", "Failed to terminate instance %s", instance.ID)
 			return ctrl.Result{}, err
 		}
-		conditions.MarkFalse(machineScope.HCMachine, infrav1.InstanceReadyCondition, clusterv1.DeletedReason, clusterv1.ConditionSeverityInfo, "")
+		machineScope.HCMachine.Status.DeleteJobID = jobId
+		machineScope.Eventf("SuccessfulInitiateTerminate", "Initiated termination of instance %s, job %s", instance.ID, jobId)
 
-		machineScope.Logger.Info("ECS instance successfully terminated", "instance-id", instance.ID)
+		machineScope.Logger.Info("ECS instance termination started", "instance-id", instance.ID, "job-id", jobId)
 
-		return ctrl.Result{}, nil
+		// requeue reconciliation until the deletion job completed
+		return ctrl.Result{RequeueAfter: DefaultReconcilerRequeue}, nil
 	}
 }
 
-func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machineScope *scope.MachineScope, _ hwbasic.ClusterScoper, ecsScope scope.ECSScope) (ctrl.Result, error) {
+func (r *HuaweiCloudMachineReconciler) reconcileNormal(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, ecsScope scope.ECSScope) (ctrl.Result, error) {
 	machineScope.Logger.Info("Reconciling HuaweiCloudMachine")
 
 	ecsSvc, err := ecs.NewService(ecsScope)
@@ -289,7 +396,7 @@ func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machin
 	}
 
 	// Find existing instance
-	instance, err := r.findInstance(machineScope, ecsSvc)
+	instance, err := r.findInstance(ctx, machineScope, ecsSvc)
 	if err != nil {
 		machineScope.Logger.Error(err, "unable to find instance")
 		conditions.MarkUnknown(machineScope.HCMachine, infrav1.InstanceReadyCondition, infrav1.InstanceNotFoundReason, "failed to find instance: %v", err)
@@ -317,12 +424,14 @@ func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machin
 		}
 
 		machineScope.Logger.Info("Creating ECS instance")
-		instance, err = ecsSvc.CreateInstance(machineScope, []byte{}, "")
+		instance, err = ecsSvc.CreateInstance(ctx, machineScope, []byte{}, "")
 		if err != nil {
 			machineScope.Logger.Error(err, "unable to create instance")
 			conditions.MarkFalse(machineScope.HCMachine, infrav1.InstanceReadyCondition, infrav1.InstanceProvisionFailedReason, clusterv1.ConditionSeverityError, "failed to create instance: %v", err)
+			machineScope.Warningf(err, "FailedCreate", "Failed to create instance")
 			return ctrl.Result{}, err
 		}
+		machineScope.Eventf("SuccessfulCreate", "Created new %s instance with id %q", machineScope.Role(), instance.ID)
 		conditions.MarkTrue(machineScope.HCMachine, infrav1.InstanceReadyCondition)
 	}
 
@@ -332,6 +441,7 @@ func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machin
 
 	existingInstanceState := machineScope.GetInstanceState()
 	machineScope.SetInstanceState(instance.State)
+	machineScope.SetAddresses(ecs.InstanceAddresses(instance))
 
 	// Proceed to reconcile the HuaweiCloudMachine state.
 	if existingInstanceState == nil || *existingInstanceState != instance.State {
@@ -367,6 +477,12 @@ func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machin
 		machineScope.SetFailureMessage(errors.Errorf("ECS instance state %q is unexpected", instance.State))
 	}
 
+	if instance.State == infrav1.InstanceStateRunning {
+		if err := r.registerWithLoadBalancers(ctx, machineScope, clusterScope, instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	machineScope.Logger.Info("done reconciling instance", "instance", instance)
 	if shouldRequeue {
 		machineScope.Logger.Info("but find the instance is pending, requeue", "instance", instance.ID)
@@ -374,3 +490,43 @@ func (r *HuaweiCloudMachineReconciler) reconcileNormal(_ context.Context, machin
 	}
 	return ctrl.Result{}, nil
 }
+
+// registerWithLoadBalancers registers a control plane instance with the control plane load balancers.
+func (r *HuaweiCloudMachineReconciler) registerWithLoadBalancers(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, instance *infrav1.Instance) error {
+	if !machineScope.IsControlPlane() || clusterScope.ControlPlaneLoadBalancer().Disabled {
+		return nil
+	}
+
+	elbSvc, err := elb.NewService(clusterScope)
+	if err != nil {
+		machineScope.Logger.Error(err, "failed to get ELB service")
+		return err
+	}
+	if err := elbSvc.RegisterInstance(ctx, instance); err != nil {
+		machineScope.Logger.Error(err, "failed to register instance with load balancers", "instance-id", instance.ID)
+		conditions.MarkFalse(machineScope.HCMachine, infrav1.ELBAttachedCondition, infrav1.ELBAttachFailedReason, clusterv1.ConditionSeverityError, "failed to register with load balancers: %v", err)
+		return err
+	}
+	conditions.MarkTrue(machineScope.HCMachine, infrav1.ELBAttachedCondition)
+	return nil
+}
+
+// deregisterFromLoadBalancers removes a control plane instance from the control plane load balancers.
+func (r *HuaweiCloudMachineReconciler) deregisterFromLoadBalancers(ctx context.Context, machineScope *scope.MachineScope, clusterScope *scope.ClusterScope, instance *infrav1.Instance) error {
+	if !machineScope.IsControlPlane() || clusterScope.ControlPlaneLoadBalancer().Disabled {
+		return nil
+	}
+
+	elbSvc, err := elb.NewService(clusterScope)
+	if err != nil {
+		machineScope.Logger.Error(err, "failed to get ELB service")
+		return err
+	}
+	if err := elbSvc.DeregisterInstance(ctx, instance); err != nil {
+		machineScope.Logger.Error(err, "failed to deregister instance from load balancers", "instance-id", instance.ID)
+		conditions.MarkFalse(machineScope.HCMachine, infrav1.ELBAttachedCondition, infrav1.ELBDetachFailedReason, clusterv1.ConditionSeverityWarning, "failed to deregister from load balancers: %v", err)
+		return err
+	}
+	conditions.MarkFalse(machineScope.HCMachine, infrav1.ELBAttachedCondition, clusterv1.DeletedReason, clusterv1.ConditionSeverityInfo, "")
+	return nil
+}
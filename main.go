@@ -17,9 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -27,7 +29,11 @@ import (
 
 	infrastructurev1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/internal/controller"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/clients"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/credentials"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/metrics"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -41,6 +47,14 @@ import (
 	// +kubebuilder:scaffold:imports
 )
 
+const (
+	// credentialsSourceStatic, credentialsSourceExternalTemporary and credentialsSourceMetadata are the
+	// values of --credentials-source.
+	credentialsSourceStatic            = "static"
+	credentialsSourceExternalTemporary = "external-temporary"
+	credentialsSourceMetadata          = "metadata"
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -60,6 +74,13 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var clusterDriftCheckInterval time.Duration
+	var credentialsSource string
+	var credentialsDir string
+	var credentialsReloadInterval time.Duration
+	var credentialsProjectID string
+	throttleOptions := clients.DefaultThrottleOptions
+	httpOptions := clients.DefaultHTTPOptions
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -71,6 +92,40 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.DurationVar(&clusterDriftCheckInterval, "cluster-drift-check-interval", 10*time.Minute,
+		"The interval at which the cluster infrastructure is checked for resources deleted out of band. "+
+			"Set to 0 to disable the periodic check.")
+	flag.Float64Var(&throttleOptions.QPS, "api-qps", throttleOptions.QPS,
+		"The maximum number of HuaweiCloud API requests per second per region and service. Set to 0 to disable rate limiting.")
+	flag.IntVar(&throttleOptions.Burst, "api-burst", throttleOptions.Burst,
+		"The maximum burst of HuaweiCloud API requests per region and service.")
+	flag.IntVar(&throttleOptions.MaxRetries, "api-max-retries", throttleOptions.MaxRetries,
+		"The number of times a throttled or transiently failed HuaweiCloud API request is retried.")
+	flag.DurationVar(&throttleOptions.RetryBaseDelay, "api-retry-base-delay", throttleOptions.RetryBaseDelay,
+		"The delay before the first retry of a HuaweiCloud API request, it doubles on each retry.")
+	flag.DurationVar(&throttleOptions.RetryMaxDelay, "api-retry-max-delay", throttleOptions.RetryMaxDelay,
+		"The maximum delay between retries of a HuaweiCloud API request.")
+	flag.DurationVar(&httpOptions.Timeout, "api-timeout", httpOptions.Timeout,
+		"The timeout of a HuaweiCloud API request.")
+	flag.StringVar(&httpOptions.ProxyURL, "api-proxy-url", "",
+		"The URL of the proxy of the HuaweiCloud API requests. If empty, HTTPS_PROXY and NO_PROXY are used.")
+	flag.StringVar(&httpOptions.CABundleFile, "api-ca-bundle", "",
+		"The path of a PEM bundle of additional certificate authorities trusted for the HuaweiCloud API endpoints.")
+	flag.StringVar(&credentialsSource, "credentials-source", credentialsSourceStatic,
+		"The source of the controller credentials: "+
+			"'static' for a permanent AK/SK read from --credentials-dir or CLOUD_SDK_AK and CLOUD_SDK_SK, "+
+			"'external-temporary' for a temporary AK/SK with the securitytoken and expiresat keys read from --credentials-dir, "+
+			"the controller does not refresh them: the files must be rewritten before their expiry by an external issuer, "+
+			"otherwise the reconciles fail once they expired, "+
+			"'metadata' for the temporary credentials of the agency of the ECS instance the manager runs on, "+
+			"which the controller refreshes before their expiry.")
+	flag.StringVar(&credentialsDir, "credentials-dir", "",
+		"The directory of the controller credentials, e.g. a mounted Secret with the accesskey, secretkey and "+
+			"optionally projectid keys. It is reloaded when the files change. If empty, CLOUD_SDK_AK and CLOUD_SDK_SK are used.")
+	flag.DurationVar(&credentialsReloadInterval, "credentials-reload-interval", 30*time.Second,
+		"The interval at which the credentials directory is checked for rotated credentials.")
+	flag.StringVar(&credentialsProjectID, "credentials-project-id", "",
+		"The project ID of the ECS metadata credentials. If empty, the project of the cluster region is looked up.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -79,6 +134,16 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if throttleOptions.QPS > 0 && throttleOptions.Burst < 1 {
+		setupLog.Error(nil, "api-burst must be at least 1 when rate limiting is enabled")
+		os.Exit(1)
+	}
+	clients.SetThrottleOptions(throttleOptions)
+	if err := clients.SetHTTPOptions(httpOptions); err != nil {
+		setupLog.Error(err, "invalid HuaweiCloud API HTTP options")
+		os.Exit(1)
+	}
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -144,31 +209,21 @@ func main() {
 		os.Exit(1)
 	}
 
-	ak := os.Getenv("CLOUD_SDK_AK")
-	if ak == "" {
-		setupLog.Error(err, "missing required environment variable CLOUD_SDK_AK")
-		os.Exit(1)
-	}
-	sk := os.Getenv("CLOUD_SDK_SK")
-	if sk == "" {
-		setupLog.Error(err, "missing required environment variable CLOUD_SDK_SK")
-		os.Exit(1)
-	}
-	setupLog.Info("using credentials", "ak", ak)
-	setupLog.Info("using credentials", "sk", sk)
-	auth, err := basic.NewCredentialsBuilder().
-		WithAk(ak).
-		WithSk(sk).
-		SafeBuild()
+	// The controller credentials are used by the clusters without an identityRef, they are optional
+	// when all the clusters reference an identity. The credential values are never logged.
+	credentialsProvider, err := setupCredentials(mgr, credentialsSource, credentialsDir,
+		credentialsReloadInterval, credentialsProjectID)
 	if err != nil {
-		setupLog.Error(err, "failed to create credentials")
+		setupLog.Error(err, "unable to set up controller credentials", "source", credentialsSource)
 		os.Exit(1)
 	}
 
 	if err = (&controller.HuaweiCloudClusterReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		Credentials: auth,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Credentials:        credentialsProvider,
+		Recorder:           mgr.GetEventRecorderFor("huaweicloudcluster-controller"),
+		DriftCheckInterval: clusterDriftCheckInterval,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HuaweiCloudCluster")
 		os.Exit(1)
@@ -176,13 +231,19 @@ func main() {
 	if err = (&controller.HuaweiCloudMachineReconciler{
 		Client:      mgr.GetClient(),
 		Scheme:      mgr.GetScheme(),
-		Credentials: auth,
+		Credentials: credentialsProvider,
+		Recorder:    mgr.GetEventRecorderFor("huaweicloudmachine-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HuaweiCloudMachine")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	if err := metrics.RegisterMachineCollector(mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to register machine metrics")
+		os.Exit(1)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -198,3 +259,56 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// setupCredentials returns the provider of the controller credentials of the source. The providers which
+// reload or renew the credentials are added to the manager.
+func setupCredentials(mgr ctrl.Manager, source, dir string, reloadInterval time.Duration,
+	projectID string) (credentials.Provider, error) {
+	switch source {
+	case credentialsSourceStatic:
+		ak, sk := os.Getenv("CLOUD_SDK_AK"), os.Getenv("CLOUD_SDK_SK")
+		switch {
+		case dir != "":
+			provider, err := credentials.NewFileProvider(dir, reloadInterval)
+			if err != nil {
+				return nil, err
+			}
+			setupLog.Info("using controller credentials from directory", "dir", dir)
+			return provider, mgr.Add(provider)
+		case ak != "" && sk != "":
+			auth, err := basic.NewCredentialsBuilder().
+				WithAk(ak).
+				WithSk(sk).
+				SafeBuild()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to create credentials")
+			}
+			setupLog.Info("using controller credentials from environment")
+			return credentials.NewStaticProvider(auth, "env"), nil
+		case ak != "" || sk != "":
+			return nil, errors.New("both CLOUD_SDK_AK and CLOUD_SDK_SK must be set to use controller credentials")
+		default:
+			setupLog.Info("no controller credentials, the clusters must reference an identity")
+			return credentials.NewStaticProvider(nil, ""), nil
+		}
+	case credentialsSourceExternalTemporary:
+		if dir == "" {
+			return nil, errors.New("--credentials-dir is required for external temporary credentials")
+		}
+		provider, err := credentials.NewExternalTemporaryFileProvider(dir, reloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		setupLog.Info("using external temporary controller credentials from directory", "dir", dir)
+		return provider, mgr.Add(provider)
+	case credentialsSourceMetadata:
+		provider, err := credentials.NewMetadataProvider(context.Background(), projectID)
+		if err != nil {
+			return nil, err
+		}
+		setupLog.Info("using controller credentials from the ECS metadata service")
+		return provider, mgr.Add(provider)
+	default:
+		return nil, errors.Errorf("unknown credentials source %q", source)
+	}
+}
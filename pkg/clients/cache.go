@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/region"
+	"github.com/pkg/errors"
+)
+
+// cacheKey identifies the SDK clients which can be shared by the reconciles.
+type cacheKey struct {
+	service    string
+	region     string
+	endpoint   string
+	credential string
+}
+
+// cacheEntry is a cached client and the last time it was used.
+// The client is built once, outside of cacheMu, as building it may resolve the project of the region
+// through IAM, which must not block the reconciles using other clients.
+type cacheEntry struct {
+	once     sync.Once
+	client   any
+	err      error
+	lastUsed time.Time
+}
+
+// cacheIdleTimeout is how long an unused client is kept, so that the clients of rotated
+// or expired credentials are eventually dropped.
+const cacheIdleTimeout = time.Hour
+
+var (
+	cacheMu sync.Mutex
+	cache   = map[cacheKey]*cacheEntry{}
+)
+
+// cached returns the client of the service for the region and credential, it is built on the first call.
+// The clients share the HTTP configuration, so the connections to the endpoints are reused.
+// The clients unused for cacheIdleTimeout are dropped when a new client is added.
+func cached[T any](service string, reg *region.Region, credential auth.ICredential,
+	builder *core.HcHttpClientBuilder, wrap func(*core.HcHttpClient) T) (T, error) {
+	key := cacheKey{
+		service:    service,
+		region:     reg.Id,
+		endpoint:   strings.Join(reg.Endpoints, ","),
+		credential: credentialIdentity(credential),
+	}
+
+	cacheMu.Lock()
+	now := time.Now()
+	entry, ok := cache[key]
+	if !ok {
+		for k, e := range cache {
+			if now.Sub(e.lastUsed) > cacheIdleTimeout {
+				delete(cache, k)
+			}
+		}
+		entry = &cacheEntry{}
+		cache[key] = entry
+	}
+	entry.lastUsed = now
+	cacheMu.Unlock()
+
+	// the concurrent callers of a new key wait for the same build
+	entry.once.Do(func() {
+		hcClient, err := builder.
+			WithRegion(reg).
+			WithCredential(copyCredential(credential)).
+			WithHttpConfig(httpConfig()).
+			SafeBuild()
+		if err != nil {
+			entry.err = errors.Wrapf(err, "failed to create %s client", service)
+			return
+		}
+		entry.client = wrap(hcClient)
+	})
+	if entry.err != nil {
+		// the failed build is not cached, the next call builds the client again
+		cacheMu.Lock()
+		if cache[key] == entry {
+			delete(cache, key)
+		}
+		cacheMu.Unlock()
+		var client T
+		return client, entry.err
+	}
+	return entry.client.(T), nil
+}
+
+// resetCache drops the cached clients, e.g. after the HTTP configuration changed.
+func resetCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache = map[cacheKey]*cacheEntry{}
+}
+
+// copyCredential returns a copy of the basic credentials. The SDK resolves the project of the region
+// into the credentials when the client is built, so they cannot be shared by clients of several regions.
+func copyCredential(credential auth.ICredential) auth.ICredential {
+	if c, ok := credential.(*basic.Credentials); ok {
+		copied := *c
+		return &copied
+	}
+	return credential
+}
+
+// credentialIdentity returns a fingerprint of the credential, the secrets are hashed so that
+// a rotated secret key gets a new client without keeping the key in the cache.
+func credentialIdentity(credential auth.ICredential) string {
+	if c, ok := credential.(*basic.Credentials); ok {
+		sum := sha256.Sum256([]byte(strings.Join([]string{c.IamEndpoint, c.AK, c.SK, c.ProjectId, c.SecurityToken}, "\x00")))
+		return fmt.Sprintf("%x", sum)
+	}
+	return fmt.Sprintf("%T/%p", credential, credential)
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/region"
+	. "github.com/onsi/gomega"
+)
+
+type testClient struct {
+	hcClient *core.HcHttpClient
+}
+
+// cachedTestClient returns the cached test client, counting the clients built.
+// The credentials have a project, so no IAM request is sent when the client is built.
+func cachedTestClient(credential auth.ICredential, builds *atomic.Int32) (*testClient, error) {
+	reg := region.NewRegion("test-region", "https://test.example.com")
+	return cached("test", reg, credential, core.NewHcHttpClientBuilder(), func(hcClient *core.HcHttpClient) *testClient {
+		builds.Add(1)
+		return &testClient{hcClient: hcClient}
+	})
+}
+
+func testCredentials(ak string) *basic.Credentials {
+	return basic.NewCredentialsBuilder().WithAk(ak).WithSk("sk").WithProjectId("project").Build()
+}
+
+func TestCachedClient(t *testing.T) {
+	g := NewWithT(t)
+	resetCache()
+	t.Cleanup(resetCache)
+
+	var builds atomic.Int32
+	clients := make([]*testClient, 10)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, err := cachedTestClient(testCredentials("ak"), &builds)
+			g.Expect(err).NotTo(HaveOccurred())
+			clients[i] = client
+		}()
+	}
+	wg.Wait()
+	g.Expect(builds.Load()).To(BeEquivalentTo(1))
+	for _, client := range clients {
+		g.Expect(client).To(BeIdenticalTo(clients[0]))
+	}
+
+	rotated, err := cachedTestClient(testCredentials("rotated"), &builds)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rotated).NotTo(BeIdenticalTo(clients[0]))
+	g.Expect(builds.Load()).To(BeEquivalentTo(2))
+}
+
+func TestCachedClientBuiltWithoutLock(t *testing.T) {
+	g := NewWithT(t)
+	resetCache()
+	t.Cleanup(resetCache)
+
+	reg := region.NewRegion("test-region", "https://test.example.com")
+	_, err := cached("test", reg, testCredentials("ak"), core.NewHcHttpClientBuilder(), func(hcClient *core.HcHttpClient) *testClient {
+		// another reconcile can get its client while this one is built
+		g.Expect(cacheMu.TryLock()).To(BeTrue())
+		cacheMu.Unlock()
+		return &testClient{hcClient: hcClient}
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+}
+
+func TestCachedClientBuildError(t *testing.T) {
+	g := NewWithT(t)
+	resetCache()
+	t.Cleanup(resetCache)
+
+	var builds atomic.Int32
+	// the builder panics without credentials
+	_, err := cachedTestClient(nil, &builds)
+	g.Expect(err).To(MatchError(ContainSubstring("failed to create test client")))
+	g.Expect(builds.Load()).To(BeEquivalentTo(0))
+	g.Expect(cache).To(BeEmpty())
+}
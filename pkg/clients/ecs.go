@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clients wraps the HuaweiCloud SDK clients used by the services to record metrics of the API calls.
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	ecssdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2/model"
+	ecsregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2/region"
+)
+
+// ECSClient wraps the ECS SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type ECSClient struct {
+	region string
+	client *ecssdk.EcsClient
+}
+
+// NewECSClient returns the ECS client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewECSClient(regionID string, credential auth.ICredential) (*ECSClient, error) {
+	reg, err := ecsregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("ecs", reg, credential, ecssdk.EcsClientBuilder(), func(hcClient *core.HcHttpClient) *ECSClient {
+		return &ECSClient{region: reg.Id, client: ecssdk.NewEcsClient(hcClient)}
+	})
+}
+
+func (c *ECSClient) CreateServers(ctx context.Context, request *model.CreateServersRequest) (*model.CreateServersResponse, error) {
+	return call(ctx, "ecs", c.region, "CreateServers", c.client.CreateServers, request)
+}
+
+func (c *ECSClient) DeleteServers(ctx context.Context, request *model.DeleteServersRequest) (*model.DeleteServersResponse, error) {
+	return call(ctx, "ecs", c.region, "DeleteServers", c.client.DeleteServers, request)
+}
+
+func (c *ECSClient) ShowJob(ctx context.Context, request *model.ShowJobRequest) (*model.ShowJobResponse, error) {
+	return call(ctx, "ecs", c.region, "ShowJob", c.client.ShowJob, request)
+}
+
+func (c *ECSClient) ShowServer(ctx context.Context, request *model.ShowServerRequest) (*model.ShowServerResponse, error) {
+	return call(ctx, "ecs", c.region, "ShowServer", c.client.ShowServer, request)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	eipsdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/eip/v2"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/eip/v2/model"
+	eipregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/eip/v2/region"
+)
+
+// EIPClient wraps the EIP SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type EIPClient struct {
+	region string
+	client *eipsdk.EipClient
+}
+
+// NewEIPClient returns the EIP client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewEIPClient(regionID string, credential auth.ICredential) (*EIPClient, error) {
+	reg, err := eipregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("eip", reg, credential, eipsdk.EipClientBuilder(), func(hcClient *core.HcHttpClient) *EIPClient {
+		return &EIPClient{region: reg.Id, client: eipsdk.NewEipClient(hcClient)}
+	})
+}
+
+func (c *EIPClient) CreatePublicip(ctx context.Context, request *model.CreatePublicipRequest) (*model.CreatePublicipResponse, error) {
+	return call(ctx, "eip", c.region, "CreatePublicip", c.client.CreatePublicip, request)
+}
+
+func (c *EIPClient) DeletePublicip(ctx context.Context, request *model.DeletePublicipRequest) (*model.DeletePublicipResponse, error) {
+	return call(ctx, "eip", c.region, "DeletePublicip", c.client.DeletePublicip, request)
+}
+
+func (c *EIPClient) ListPublicips(ctx context.Context, request *model.ListPublicipsRequest) (*model.ListPublicipsResponse, error) {
+	return call(ctx, "eip", c.region, "ListPublicips", c.client.ListPublicips, request)
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	elbsdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	elbregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/region"
+)
+
+// ELBClient wraps the ELB SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type ELBClient struct {
+	region string
+	client *elbsdk.ElbClient
+}
+
+// NewELBClient returns the ELB client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewELBClient(regionID string, credential auth.ICredential) (*ELBClient, error) {
+	reg, err := elbregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("elb", reg, credential, elbsdk.ElbClientBuilder(), func(hcClient *core.HcHttpClient) *ELBClient {
+		return &ELBClient{region: reg.Id, client: elbsdk.NewElbClient(hcClient)}
+	})
+}
+
+func (c *ELBClient) CreateLoadBalancer(ctx context.Context, request *model.CreateLoadBalancerRequest) (*model.CreateLoadBalancerResponse, error) {
+	return call(ctx, "elb", c.region, "CreateLoadBalancer", c.client.CreateLoadBalancer, request)
+}
+
+func (c *ELBClient) DeleteLoadBalancer(ctx context.Context, request *model.DeleteLoadBalancerRequest) (*model.DeleteLoadBalancerResponse, error) {
+	return call(ctx, "elb", c.region, "DeleteLoadBalancer", c.client.DeleteLoadBalancer, request)
+}
+
+func (c *ELBClient) ListLoadBalancers(ctx context.Context, request *model.ListLoadBalancersRequest) (*model.ListLoadBalancersResponse, error) {
+	return call(ctx, "elb", c.region, "ListLoadBalancers", c.client.ListLoadBalancers, request)
+}
+
+func (c *ELBClient) ShowLoadBalancer(ctx context.Context, request *model.ShowLoadBalancerRequest) (*model.ShowLoadBalancerResponse, error) {
+	return call(ctx, "elb", c.region, "ShowLoadBalancer", c.client.ShowLoadBalancer, request)
+}
+
+func (c *ELBClient) ListAvailabilityZones(ctx context.Context, request *model.ListAvailabilityZonesRequest) (*model.ListAvailabilityZonesResponse, error) {
+	return call(ctx, "elb", c.region, "ListAvailabilityZones", c.client.ListAvailabilityZones, request)
+}
+
+func (c *ELBClient) ListFlavors(ctx context.Context, request *model.ListFlavorsRequest) (*model.ListFlavorsResponse, error) {
+	return call(ctx, "elb", c.region, "ListFlavors", c.client.ListFlavors, request)
+}
+
+func (c *ELBClient) CreateListener(ctx context.Context, request *model.CreateListenerRequest) (*model.CreateListenerResponse, error) {
+	return call(ctx, "elb", c.region, "CreateListener", c.client.CreateListener, request)
+}
+
+func (c *ELBClient) DeleteListener(ctx context.Context, request *model.DeleteListenerRequest) (*model.DeleteListenerResponse, error) {
+	return call(ctx, "elb", c.region, "DeleteListener", c.client.DeleteListener, request)
+}
+
+func (c *ELBClient) ListListeners(ctx context.Context, request *model.ListListenersRequest) (*model.ListListenersResponse, error) {
+	return call(ctx, "elb", c.region, "ListListeners", c.client.ListListeners, request)
+}
+
+func (c *ELBClient) ShowListener(ctx context.Context, request *model.ShowListenerRequest) (*model.ShowListenerResponse, error) {
+	return call(ctx, "elb", c.region, "ShowListener", c.client.ShowListener, request)
+}
+
+func (c *ELBClient) UpdateListener(ctx context.Context, request *model.UpdateListenerRequest) (*model.UpdateListenerResponse, error) {
+	return call(ctx, "elb", c.region, "UpdateListener", c.client.UpdateListener, request)
+}
+
+// detachListenerIpGroupRequest is the UpdateListener request setting the IP address group of the listener to null.
+// The SDK model omits a nil IP address group, so it cannot detach the IP address group from the listener.
+type detachListenerIpGroupRequest struct {
+	ListenerId string                       `json:"listener_id"`
+	Body       *detachListenerIpGroupOption `json:"body,omitempty"`
+}
+
+type detachListenerIpGroupOption struct {
+	Listener struct {
+		Ipgroup *model.UpdateListenerIpGroupOption `json:"ipgroup"`
+	} `json:"listener"`
+}
+
+// DetachListenerIpGroup removes the IP address group from the listener, so that the IP address group can be deleted.
+func (c *ELBClient) DetachListenerIpGroup(ctx context.Context, listenerId string) (*model.UpdateListenerResponse, error) {
+	request := &detachListenerIpGroupRequest{ListenerId: listenerId, Body: &detachListenerIpGroupOption{}}
+	return call(ctx, "elb", c.region, "UpdateListener", func(request *detachListenerIpGroupRequest) (*model.UpdateListenerResponse, error) {
+		response, err := c.client.HcClient.Sync(request, elbsdk.GenReqDefForUpdateListener())
+		if err != nil {
+			return nil, err
+		}
+		return response.(*model.UpdateListenerResponse), nil
+	}, request)
+}
+
+func (c *ELBClient) CreatePool(ctx context.Context, request *model.CreatePoolRequest) (*model.CreatePoolResponse, error) {
+	return call(ctx, "elb", c.region, "CreatePool", c.client.CreatePool, request)
+}
+
+func (c *ELBClient) DeletePool(ctx context.Context, request *model.DeletePoolRequest) (*model.DeletePoolResponse, error) {
+	return call(ctx, "elb", c.region, "DeletePool", c.client.DeletePool, request)
+}
+
+func (c *ELBClient) ShowPool(ctx context.Context, request *model.ShowPoolRequest) (*model.ShowPoolResponse, error) {
+	return call(ctx, "elb", c.region, "ShowPool", c.client.ShowPool, request)
+}
+
+func (c *ELBClient) CreateMember(ctx context.Context, request *model.CreateMemberRequest) (*model.CreateMemberResponse, error) {
+	return call(ctx, "elb", c.region, "CreateMember", c.client.CreateMember, request)
+}
+
+func (c *ELBClient) DeleteMember(ctx context.Context, request *model.DeleteMemberRequest) (*model.DeleteMemberResponse, error) {
+	return call(ctx, "elb", c.region, "DeleteMember", c.client.DeleteMember, request)
+}
+
+func (c *ELBClient) ListMembers(ctx context.Context, request *model.ListMembersRequest) (*model.ListMembersResponse, error) {
+	return call(ctx, "elb", c.region, "ListMembers", c.client.ListMembers, request)
+}
+
+func (c *ELBClient) CreateHealthMonitor(ctx context.Context, request *model.CreateHealthMonitorRequest) (*model.CreateHealthMonitorResponse, error) {
+	return call(ctx, "elb", c.region, "CreateHealthMonitor", c.client.CreateHealthMonitor, request)
+}
+
+func (c *ELBClient) DeleteHealthMonitor(ctx context.Context, request *model.DeleteHealthMonitorRequest) (*model.DeleteHealthMonitorResponse, error) {
+	return call(ctx, "elb", c.region, "DeleteHealthMonitor", c.client.DeleteHealthMonitor, request)
+}
+
+func (c *ELBClient) ShowHealthMonitor(ctx context.Context, request *model.ShowHealthMonitorRequest) (*model.ShowHealthMonitorResponse, error) {
+	return call(ctx, "elb", c.region, "ShowHealthMonitor", c.client.ShowHealthMonitor, request)
+}
+
+func (c *ELBClient) UpdateHealthMonitor(ctx context.Context, request *model.UpdateHealthMonitorRequest) (*model.UpdateHealthMonitorResponse, error) {
+	return call(ctx, "elb", c.region, "UpdateHealthMonitor", c.client.UpdateHealthMonitor, request)
+}
+
+func (c *ELBClient) CreateIpGroup(ctx context.Context, request *model.CreateIpGroupRequest) (*model.CreateIpGroupResponse, error) {
+	return call(ctx, "elb", c.region, "CreateIpGroup", c.client.CreateIpGroup, request)
+}
+
+func (c *ELBClient) DeleteIpGroup(ctx context.Context, request *model.DeleteIpGroupRequest) (*model.DeleteIpGroupResponse, error) {
+	return call(ctx, "elb", c.region, "DeleteIpGroup", c.client.DeleteIpGroup, request)
+}
+
+func (c *ELBClient) ListIpGroups(ctx context.Context, request *model.ListIpGroupsRequest) (*model.ListIpGroupsResponse, error) {
+	return call(ctx, "elb", c.region, "ListIpGroups", c.client.ListIpGroups, request)
+}
+
+func (c *ELBClient) ShowIpGroup(ctx context.Context, request *model.ShowIpGroupRequest) (*model.ShowIpGroupResponse, error) {
+	return call(ctx, "elb", c.region, "ShowIpGroup", c.client.ShowIpGroup, request)
+}
+
+func (c *ELBClient) UpdateIpGroup(ctx context.Context, request *model.UpdateIpGroupRequest) (*model.UpdateIpGroupResponse, error) {
+	return call(ctx, "elb", c.region, "UpdateIpGroup", c.client.UpdateIpGroup, request)
+}
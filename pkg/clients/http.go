@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/config"
+	"github.com/pkg/errors"
+)
+
+// HTTPOptions configures the HTTP client shared by all the HuaweiCloud SDK clients.
+type HTTPOptions struct {
+	// Timeout is the timeout of a HuaweiCloud API request.
+	Timeout time.Duration
+	// ProxyURL is the URL of the proxy of the API requests. If empty, the proxy is read
+	// from the HTTPS_PROXY and NO_PROXY environment variables.
+	ProxyURL string
+	// CABundleFile is the path of a PEM bundle of the certificate authorities trusted
+	// in addition to the system ones, e.g. for a private cloud endpoint.
+	CABundleFile string
+}
+
+// DefaultHTTPOptions are the HTTP options used unless SetHTTPOptions is called.
+var DefaultHTTPOptions = HTTPOptions{
+	Timeout: 60 * time.Second,
+}
+
+var (
+	httpMu     sync.Mutex
+	sharedHTTP *config.HttpConfig
+)
+
+// SetHTTPOptions sets the HTTP options of all the clients, it is meant to be called on startup.
+func SetHTTPOptions(options HTTPOptions) error {
+	httpConfig, err := newHTTPConfig(options)
+	if err != nil {
+		return err
+	}
+
+	httpMu.Lock()
+	sharedHTTP = httpConfig
+	httpMu.Unlock()
+
+	resetCache()
+	return nil
+}
+
+// httpConfig returns the shared HTTP configuration of the SDK clients.
+func httpConfig() *config.HttpConfig {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+
+	if sharedHTTP == nil {
+		// the default options are always valid
+		sharedHTTP, _ = newHTTPConfig(DefaultHTTPOptions)
+	}
+	return sharedHTTP
+}
+
+func newHTTPConfig(options HTTPOptions) (*config.HttpConfig, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid proxy URL %q", options.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if options.CABundleFile != "" {
+		bundle, err := os.ReadFile(options.CABundleFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read CA bundle %s", options.CABundleFile)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, errors.Errorf("no certificate found in CA bundle %s", options.CABundleFile)
+		}
+		transport.TLSClientConfig = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+
+	// The transport takes precedence over the proxy and TLS settings of the SDK configuration.
+	return config.DefaultHttpConfig().
+		WithTimeout(options.Timeout).
+		WithHttpTransport(transport), nil
+}
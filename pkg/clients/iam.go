@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	iamsdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/model"
+	iamregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/region"
+)
+
+// IAMClient wraps the IAM SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type IAMClient struct {
+	region string
+	client *iamsdk.IamClient
+}
+
+// NewIAMClient returns the IAM client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewIAMClient(regionID string, credential auth.ICredential) (*IAMClient, error) {
+	reg, err := iamregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("iam", reg, credential, iamsdk.IamClientBuilder(), func(hcClient *core.HcHttpClient) *IAMClient {
+		return &IAMClient{region: reg.Id, client: iamsdk.NewIamClient(hcClient)}
+	})
+}
+
+func (c *IAMClient) CreateTemporaryAccessKeyByAgency(ctx context.Context, request *model.CreateTemporaryAccessKeyByAgencyRequest) (*model.CreateTemporaryAccessKeyByAgencyResponse, error) {
+	return call(ctx, "iam", c.region, "CreateTemporaryAccessKeyByAgency", c.client.CreateTemporaryAccessKeyByAgency, request)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	natsdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/nat/v2"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/nat/v2/model"
+	natregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/nat/v2/region"
+)
+
+// NATClient wraps the NAT SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type NATClient struct {
+	region string
+	client *natsdk.NatClient
+}
+
+// NewNATClient returns the NAT client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewNATClient(regionID string, credential auth.ICredential) (*NATClient, error) {
+	reg, err := natregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("nat", reg, credential, natsdk.NatClientBuilder(), func(hcClient *core.HcHttpClient) *NATClient {
+		return &NATClient{region: reg.Id, client: natsdk.NewNatClient(hcClient)}
+	})
+}
+
+func (c *NATClient) CreateNatGateway(ctx context.Context, request *model.CreateNatGatewayRequest) (*model.CreateNatGatewayResponse, error) {
+	return call(ctx, "nat", c.region, "CreateNatGateway", c.client.CreateNatGateway, request)
+}
+
+func (c *NATClient) DeleteNatGateway(ctx context.Context, request *model.DeleteNatGatewayRequest) (*model.DeleteNatGatewayResponse, error) {
+	return call(ctx, "nat", c.region, "DeleteNatGateway", c.client.DeleteNatGateway, request)
+}
+
+func (c *NATClient) ListNatGateways(ctx context.Context, request *model.ListNatGatewaysRequest) (*model.ListNatGatewaysResponse, error) {
+	return call(ctx, "nat", c.region, "ListNatGateways", c.client.ListNatGateways, request)
+}
+
+func (c *NATClient) CreateNatGatewaySnatRule(ctx context.Context, request *model.CreateNatGatewaySnatRuleRequest) (*model.CreateNatGatewaySnatRuleResponse, error) {
+	return call(ctx, "nat", c.region, "CreateNatGatewaySnatRule", c.client.CreateNatGatewaySnatRule, request)
+}
+
+func (c *NATClient) DeleteNatGatewaySnatRule(ctx context.Context, request *model.DeleteNatGatewaySnatRuleRequest) (*model.DeleteNatGatewaySnatRuleResponse, error) {
+	return call(ctx, "nat", c.region, "DeleteNatGatewaySnatRule", c.client.DeleteNatGatewaySnatRule, request)
+}
+
+func (c *NATClient) ListNatGatewaySnatRules(ctx context.Context, request *model.ListNatGatewaySnatRulesRequest) (*model.ListNatGatewaySnatRulesResponse, error) {
+	return call(ctx, "nat", c.region, "ListNatGatewaySnatRules", c.client.ListNatGatewaySnatRules, request)
+}
+
+func (c *NATClient) DeleteNatGatewayDnatRule(ctx context.Context, request *model.DeleteNatGatewayDnatRuleRequest) (*model.DeleteNatGatewayDnatRuleResponse, error) {
+	return call(ctx, "nat", c.region, "DeleteNatGatewayDnatRule", c.client.DeleteNatGatewayDnatRule, request)
+}
+
+func (c *NATClient) ListNatGatewayDnatRules(ctx context.Context, request *model.ListNatGatewayDnatRulesRequest) (*model.ListNatGatewayDnatRulesResponse, error) {
+	return call(ctx, "nat", c.region, "ListNatGatewayDnatRules", c.client.ListNatGatewayDnatRules, request)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/metrics"
+)
+
+// ThrottleOptions configures the client-side rate limiting and the retries of the HuaweiCloud API calls.
+type ThrottleOptions struct {
+	// QPS is the number of requests per second allowed per region and service, 0 disables rate limiting.
+	QPS float64
+	// Burst is the maximum number of requests sent at once per region and service.
+	Burst int
+	// MaxRetries is the number of times a throttled or failed request is retried.
+	MaxRetries int
+	// RetryBaseDelay is the delay before the first retry, it doubles on each retry.
+	RetryBaseDelay time.Duration
+	// RetryMaxDelay caps the delay between retries.
+	RetryMaxDelay time.Duration
+}
+
+// DefaultThrottleOptions are the throttle options used unless SetThrottleOptions is called.
+var DefaultThrottleOptions = ThrottleOptions{
+	QPS:            10,
+	Burst:          20,
+	MaxRetries:     5,
+	RetryBaseDelay: 500 * time.Millisecond,
+	RetryMaxDelay:  30 * time.Second,
+}
+
+var (
+	throttleMu      sync.Mutex
+	throttleOptions = DefaultThrottleOptions
+	// limiters holds the token bucket of each region and service, shared by all the clients.
+	limiters = map[string]*rate.Limiter{}
+)
+
+// SetThrottleOptions sets the throttle options of all the clients, it is meant to be called on startup.
+func SetThrottleOptions(options ThrottleOptions) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	throttleOptions = options
+	limiters = map[string]*rate.Limiter{}
+}
+
+// throttle returns the throttle options and the rate limiter of the region and service.
+func throttle(service, region string) (ThrottleOptions, *rate.Limiter) {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+
+	key := region + "/" + service
+	limiter, ok := limiters[key]
+	if !ok {
+		limit := rate.Limit(throttleOptions.QPS)
+		if throttleOptions.QPS <= 0 {
+			limit = rate.Inf
+		}
+		limiter = rate.NewLimiter(limit, throttleOptions.Burst)
+		limiters[key] = limiter
+	}
+	return throttleOptions, limiter
+}
+
+// call waits for the rate limiter of the region and service, then calls the API operation.
+// Throttled requests and transient server errors are retried with exponential backoff and jitter.
+// The wait for the rate limiter and the retries are stopped when the context is done.
+func call[Req, Resp any](ctx context.Context, service, region, operation string, fn func(Req) (Resp, error), request Req) (Resp, error) {
+	options, limiter := throttle(service, region)
+	backoff := newBackoff(options)
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			var response Resp
+			return response, err
+		}
+
+		response, err := metrics.Observe(service, operation, fn, request)
+		if !shouldRetry(err, attempt, options.MaxRetries) {
+			return response, err
+		}
+
+		delay := backoff.Step()
+		klog.V(2).Infof("Retrying %s %s in region %s in %s, attempt %d: %s",
+			service, operation, region, delay, attempt+1, ecserrors.Describe(err))
+		metrics.ObserveRetry(service, operation)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return response, errors.Wrapf(ctx.Err(), "%s %s canceled while waiting to retry: %s",
+				service, operation, ecserrors.Describe(err))
+		case <-timer.C:
+		}
+	}
+}
+
+// newBackoff returns the delays between the retries, doubling from the base delay up to the max delay.
+func newBackoff(options ThrottleOptions) *wait.Backoff {
+	return &wait.Backoff{
+		Duration: options.RetryBaseDelay,
+		Factor:   2,
+		Jitter:   1,
+		Steps:    options.MaxRetries,
+		Cap:      options.RetryMaxDelay,
+	}
+}
+
+// shouldRetry returns true if the request failed with a retryable error and the retries are not exhausted.
+func shouldRetry(err error, attempt, maxRetries int) bool {
+	return err != nil && ecserrors.IsRetryable(err) && attempt < maxRetries
+}
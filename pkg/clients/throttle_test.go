@@ -0,0 +1,209 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/sdkerr"
+	. "github.com/onsi/gomega"
+)
+
+var (
+	errThrottled = &sdkerr.ServiceResponseError{StatusCode: http.StatusTooManyRequests, ErrorCode: "APIGW.0308"}
+	errNotFound  = &sdkerr.ServiceResponseError{StatusCode: http.StatusNotFound, ErrorCode: "Ecs.0114"}
+)
+
+func TestShouldRetry(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		attempt int
+		want    bool
+	}{
+		{
+			name: "success",
+		},
+		{
+			name: "throttled",
+			err:  errThrottled,
+			want: true,
+		},
+		{
+			name:    "throttled on the last retry",
+			err:     errThrottled,
+			attempt: 2,
+			want:    true,
+		},
+		{
+			name:    "throttled with retries exhausted",
+			err:     errThrottled,
+			attempt: 3,
+		},
+		{
+			name: "service unavailable",
+			err:  &sdkerr.ServiceResponseError{StatusCode: http.StatusServiceUnavailable},
+			want: true,
+		},
+		{
+			name: "internal server error",
+			err:  &sdkerr.ServiceResponseError{StatusCode: http.StatusInternalServerError},
+		},
+		{
+			name: "not found",
+			err:  errNotFound,
+		},
+		{
+			name: "connection error",
+			err:  errors.New("connection refused"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(shouldRetry(tt.err, tt.attempt, 3)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestNewBackoff(t *testing.T) {
+	g := NewWithT(t)
+	backoff := newBackoff(ThrottleOptions{
+		MaxRetries:     5,
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  400 * time.Millisecond,
+	})
+
+	// the delays double up to the max delay, the jitter adds up to the delay itself
+	for _, base := range []time.Duration{100, 200, 400, 400, 400} {
+		base *= time.Millisecond
+		delay := backoff.Step()
+		g.Expect(delay).To(BeNumerically(">=", base))
+		g.Expect(delay).To(BeNumerically("<=", 2*base))
+	}
+}
+
+// setTestThrottleOptions sets throttle options without rate limiting and with short retry delays.
+func setTestThrottleOptions(t *testing.T, maxRetries int, retryDelay time.Duration) {
+	SetThrottleOptions(ThrottleOptions{
+		MaxRetries:     maxRetries,
+		RetryBaseDelay: retryDelay,
+		RetryMaxDelay:  retryDelay,
+	})
+	t.Cleanup(func() { SetThrottleOptions(DefaultThrottleOptions) })
+}
+
+func TestCallRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{
+			name:         "success",
+			wantAttempts: 1,
+		},
+		{
+			name:         "throttled then success",
+			errs:         []error{errThrottled, errThrottled},
+			wantAttempts: 3,
+		},
+		{
+			name:         "throttled until retries are exhausted",
+			errs:         []error{errThrottled, errThrottled, errThrottled, errThrottled},
+			wantErr:      errThrottled,
+			wantAttempts: 3,
+		},
+		{
+			name:         "not retryable",
+			errs:         []error{errNotFound},
+			wantErr:      errNotFound,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			setTestThrottleOptions(t, 2, time.Millisecond)
+
+			attempts := 0
+			fn := func(request string) (string, error) {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return "", tt.errs[attempts-1]
+				}
+				return request, nil
+			}
+
+			response, err := call(context.Background(), "test", "region", tt.name, fn, "request")
+			if tt.wantErr != nil {
+				g.Expect(err).To(MatchError(tt.wantErr))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(response).To(Equal("request"))
+			}
+			g.Expect(attempts).To(Equal(tt.wantAttempts))
+		})
+	}
+}
+
+func TestCallCanceledWhileWaitingToRetry(t *testing.T) {
+	g := NewWithT(t)
+	setTestThrottleOptions(t, 5, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	fn := func(string) (string, error) {
+		attempts++
+		cancel()
+		return "", errThrottled
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := call(ctx, "test", "region", "canceled", fn, "request")
+		done <- err
+	}()
+	g.Eventually(done).Should(Receive(MatchError(context.Canceled)))
+	g.Expect(attempts).To(Equal(1))
+}
+
+func TestCallCanceledWhileRateLimited(t *testing.T) {
+	g := NewWithT(t)
+	SetThrottleOptions(ThrottleOptions{QPS: 0.001, Burst: 1})
+	t.Cleanup(func() { SetThrottleOptions(DefaultThrottleOptions) })
+
+	attempts := 0
+	fn := func(string) (string, error) {
+		attempts++
+		return "", nil
+	}
+	// the first request takes the only token of the bucket
+	_, err := call(context.Background(), "test", "region", "rate-limited", fn, "request")
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = call(ctx, "test", "region", "rate-limited", fn, "request")
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(attempts).To(Equal(1))
+}
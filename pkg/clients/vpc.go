@@ -0,0 +1,106 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
+	vpcsdk "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2"
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
+	vpcregion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/region"
+)
+
+// VPCClient wraps the VPC SDK client, every call is rate limited, retried
+// on throttling and records the API request metrics.
+type VPCClient struct {
+	region string
+	client *vpcsdk.VpcClient
+}
+
+// NewVPCClient returns the VPC client of the region for the credential.
+// The clients are cached per region, endpoint and credential, and shared by the reconciles.
+func NewVPCClient(regionID string, credential auth.ICredential) (*VPCClient, error) {
+	reg, err := vpcregion.SafeValueOf(regionID)
+	if err != nil {
+		return nil, err
+	}
+	return cached("vpc", reg, credential, vpcsdk.VpcClientBuilder(), func(hcClient *core.HcHttpClient) *VPCClient {
+		return &VPCClient{region: reg.Id, client: vpcsdk.NewVpcClient(hcClient)}
+	})
+}
+
+func (c *VPCClient) CreateVpc(ctx context.Context, request *model.CreateVpcRequest) (*model.CreateVpcResponse, error) {
+	return call(ctx, "vpc", c.region, "CreateVpc", c.client.CreateVpc, request)
+}
+
+func (c *VPCClient) DeleteVpc(ctx context.Context, request *model.DeleteVpcRequest) (*model.DeleteVpcResponse, error) {
+	return call(ctx, "vpc", c.region, "DeleteVpc", c.client.DeleteVpc, request)
+}
+
+func (c *VPCClient) ShowVpc(ctx context.Context, request *model.ShowVpcRequest) (*model.ShowVpcResponse, error) {
+	return call(ctx, "vpc", c.region, "ShowVpc", c.client.ShowVpc, request)
+}
+
+func (c *VPCClient) CreateSubnet(ctx context.Context, request *model.CreateSubnetRequest) (*model.CreateSubnetResponse, error) {
+	return call(ctx, "vpc", c.region, "CreateSubnet", c.client.CreateSubnet, request)
+}
+
+func (c *VPCClient) DeleteSubnet(ctx context.Context, request *model.DeleteSubnetRequest) (*model.DeleteSubnetResponse, error) {
+	return call(ctx, "vpc", c.region, "DeleteSubnet", c.client.DeleteSubnet, request)
+}
+
+func (c *VPCClient) ListSubnets(ctx context.Context, request *model.ListSubnetsRequest) (*model.ListSubnetsResponse, error) {
+	return call(ctx, "vpc", c.region, "ListSubnets", c.client.ListSubnets, request)
+}
+
+func (c *VPCClient) ShowSubnet(ctx context.Context, request *model.ShowSubnetRequest) (*model.ShowSubnetResponse, error) {
+	return call(ctx, "vpc", c.region, "ShowSubnet", c.client.ShowSubnet, request)
+}
+
+func (c *VPCClient) UpdateSubnet(ctx context.Context, request *model.UpdateSubnetRequest) (*model.UpdateSubnetResponse, error) {
+	return call(ctx, "vpc", c.region, "UpdateSubnet", c.client.UpdateSubnet, request)
+}
+
+func (c *VPCClient) CreateSecurityGroup(ctx context.Context, request *model.CreateSecurityGroupRequest) (*model.CreateSecurityGroupResponse, error) {
+	return call(ctx, "vpc", c.region, "CreateSecurityGroup", c.client.CreateSecurityGroup, request)
+}
+
+func (c *VPCClient) ListSecurityGroups(ctx context.Context, request *model.ListSecurityGroupsRequest) (*model.ListSecurityGroupsResponse, error) {
+	return call(ctx, "vpc", c.region, "ListSecurityGroups", c.client.ListSecurityGroups, request)
+}
+
+func (c *VPCClient) ListSecurityGroupRules(ctx context.Context, request *model.ListSecurityGroupRulesRequest) (*model.ListSecurityGroupRulesResponse, error) {
+	return call(ctx, "vpc", c.region, "ListSecurityGroupRules", c.client.ListSecurityGroupRules, request)
+}
+
+func (c *VPCClient) NeutronCreateSecurityGroupRule(ctx context.Context, request *model.NeutronCreateSecurityGroupRuleRequest) (*model.NeutronCreateSecurityGroupRuleResponse, error) {
+	return call(ctx, "vpc", c.region, "NeutronCreateSecurityGroupRule", c.client.NeutronCreateSecurityGroupRule, request)
+}
+
+func (c *VPCClient) NeutronDeleteSecurityGroup(ctx context.Context, request *model.NeutronDeleteSecurityGroupRequest) (*model.NeutronDeleteSecurityGroupResponse, error) {
+	return call(ctx, "vpc", c.region, "NeutronDeleteSecurityGroup", c.client.NeutronDeleteSecurityGroup, request)
+}
+
+func (c *VPCClient) NeutronDeleteSecurityGroupRule(ctx context.Context, request *model.NeutronDeleteSecurityGroupRuleRequest) (*model.NeutronDeleteSecurityGroupRuleResponse, error) {
+	return call(ctx, "vpc", c.region, "NeutronDeleteSecurityGroupRule", c.client.NeutronDeleteSecurityGroupRule, request)
+}
+
+func (c *VPCClient) NeutronListSecurityGroupRules(ctx context.Context, request *model.NeutronListSecurityGroupRulesRequest) (*model.NeutronListSecurityGroupRulesResponse, error) {
+	return call(ctx, "vpc", c.region, "NeutronListSecurityGroupRules", c.client.NeutronListSecurityGroupRules, request)
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package credentials provides the controller credentials, used by the clusters without an identityRef.
+// The credentials may change while the manager runs, so they are read again on every reconcile.
+package credentials
+
+import (
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/pkg/errors"
+
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/metrics"
+)
+
+// Provider provides the current controller credentials.
+type Provider interface {
+	// Credentials returns the credentials to use for a new reconcile, or nil if the controller has no credentials.
+	// It fails when the credentials cannot be used anymore, e.g. temporary credentials which expired.
+	Credentials() (*basic.Credentials, error)
+}
+
+// staticProvider provides credentials which never change.
+type staticProvider struct {
+	credentials *basic.Credentials
+}
+
+// NewStaticProvider returns a provider of fixed credentials, e.g. read from the environment on startup.
+// A nil credentials provides no credentials.
+func NewStaticProvider(credentials *basic.Credentials, source string) Provider {
+	if credentials != nil {
+		metrics.SetControllerCredentials(credentials.AK, source, time.Time{})
+	}
+	return &staticProvider{credentials: credentials}
+}
+
+// Credentials implements Provider.
+func (p *staticProvider) Credentials() (*basic.Credentials, error) {
+	return p.credentials, nil
+}
+
+// checkExpiry returns an error when temporary credentials expiring at expiresAt have expired.
+// A zero expiry is the expiry of permanent credentials, which never expire.
+func checkExpiry(expiresAt time.Time) error {
+	if !expiresAt.IsZero() && !time.Now().Before(expiresAt) {
+		return errors.Errorf("the credentials expired at %s", expiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/metrics"
+)
+
+const (
+	// AccessKeyFile is the file of the access key ID in the credentials directory.
+	AccessKeyFile = "accesskey"
+
+	// SecretKeyFile is the file of the secret access key in the credentials directory.
+	SecretKeyFile = "secretkey"
+
+	// ProjectIDFile is the optional file of the project ID in the credentials directory.
+	// The project of the cluster region is looked up when it is not set.
+	ProjectIDFile = "projectid"
+
+	// SecurityTokenFile is the file of the security token of temporary credentials in the credentials directory.
+	SecurityTokenFile = "securitytoken"
+
+	// ExpiresAtFile is the file of the RFC 3339 expiry of temporary credentials in the credentials directory.
+	ExpiresAtFile = "expiresat"
+
+	// fileSource and externalTemporaryFileSource are the sources of the file credentials reported in the metrics.
+	fileSource                  = "file"
+	externalTemporaryFileSource = "external-temporary"
+)
+
+// FileProvider provides the credentials read from a directory, typically a mounted Secret.
+// The directory is polled, so that rotated credentials are used by the next reconciles without restarting the manager.
+type FileProvider struct {
+	dir      string
+	interval time.Duration
+	// externalTemporary requires a security token and an expiry in the directory.
+	externalTemporary bool
+
+	mu          sync.RWMutex
+	credentials *basic.Credentials
+	// checksum is the checksum of the files the credentials were built from.
+	checksum  [sha256.Size]byte
+	expiresAt time.Time
+}
+
+// NewFileProvider returns a provider of the credentials in the directory, which are read once before returning.
+// The provider must be added to the manager to reload the credentials every interval.
+func NewFileProvider(dir string, interval time.Duration) (*FileProvider, error) {
+	p := &FileProvider{dir: dir, interval: interval}
+	if _, err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewExternalTemporaryFileProvider returns a provider of the temporary credentials in the directory, which must
+// also have the security token and the expiry of the credentials. Unlike MetadataProvider, the provider does not
+// refresh the credentials, they must be rotated before their expiry by an external issuer of the files, e.g. a
+// Secret updated by an external secrets operator. The provider only reloads the rotated files, warns when the
+// credentials are about to expire and rejects expired credentials.
+func NewExternalTemporaryFileProvider(dir string, interval time.Duration) (*FileProvider, error) {
+	p := &FileProvider{dir: dir, interval: interval, externalTemporary: true}
+	if _, err := p.reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Credentials implements Provider, it fails once the temporary credentials expired without being rotated.
+func (p *FileProvider) Credentials() (*basic.Credentials, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if err := checkExpiry(p.expiresAt); err != nil {
+		return nil, errors.Wrapf(err, "controller credentials from %s", p.dir)
+	}
+	return p.credentials, nil
+}
+
+// Start implements manager.Runnable, it reloads the credentials until the context is done.
+func (p *FileProvider) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(context.Context) {
+		reloaded, err := p.reload()
+		if err != nil {
+			// The values are not part of the errors, only the file names.
+			klog.Errorf("Failed to reload the controller credentials from %s: %v", p.dir, err)
+			metrics.ObserveControllerCredentialsReloadError()
+			return
+		}
+		if reloaded {
+			klog.Infof("Reloaded the controller credentials from %s", p.dir)
+		}
+		if expiresAt := p.expiry(); !expiresAt.IsZero() && time.Until(expiresAt) < refreshWindow {
+			klog.Warningf("The controller credentials from %s expire at %s and have not been rotated by their issuer yet",
+				p.dir, expiresAt.Format(time.RFC3339))
+		}
+	}, p.interval)
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the credentials are needed by all the replicas.
+func (p *FileProvider) NeedLeaderElection() bool {
+	return false
+}
+
+// expiry returns the expiry of the temporary credentials, it is zero for permanent credentials.
+func (p *FileProvider) expiry() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.expiresAt
+}
+
+// reload reads the credentials files and replaces the credentials when they changed.
+func (p *FileProvider) reload() (bool, error) {
+	ak, err := p.readFile(AccessKeyFile, true)
+	if err != nil {
+		return false, err
+	}
+	sk, err := p.readFile(SecretKeyFile, true)
+	if err != nil {
+		return false, err
+	}
+	projectID, err := p.readFile(ProjectIDFile, false)
+	if err != nil {
+		return false, err
+	}
+	securityToken, err := p.readFile(SecurityTokenFile, p.externalTemporary)
+	if err != nil {
+		return false, err
+	}
+	expiry, err := p.readFile(ExpiresAtFile, p.externalTemporary)
+	if err != nil {
+		return false, err
+	}
+
+	// The expiry is checked on every reload, so that unchanged credentials which expired are reported.
+	var expiresAt time.Time
+	if expiry != "" {
+		if expiresAt, err = time.Parse(time.RFC3339, expiry); err != nil {
+			return false, errors.Wrapf(err, "invalid expiry in %s", filepath.Join(p.dir, ExpiresAtFile))
+		}
+		if err := checkExpiry(expiresAt); err != nil {
+			return false, errors.Wrapf(err, "invalid credentials in %s", p.dir)
+		}
+	}
+
+	checksum := sha256.Sum256([]byte(strings.Join([]string{ak, sk, projectID, securityToken, expiry}, "\x00")))
+	p.mu.RLock()
+	unchanged := p.credentials != nil && checksum == p.checksum
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	credentials, err := basic.NewCredentialsBuilder().
+		WithAk(ak).
+		WithSk(sk).
+		WithSecurityToken(securityToken).
+		WithProjectId(projectID).
+		SafeBuild()
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to create credentials from %s", p.dir)
+	}
+
+	p.mu.Lock()
+	p.credentials = credentials
+	p.checksum = checksum
+	p.expiresAt = expiresAt
+	p.mu.Unlock()
+
+	source := fileSource
+	if p.externalTemporary {
+		source = externalTemporaryFileSource
+	}
+	metrics.SetControllerCredentials(ak, source, expiresAt)
+	return true, nil
+}
+
+// readFile returns the trimmed content of the file of the credentials directory.
+// A missing optional file is empty.
+func (p *FileProvider) readFile(name string, required bool) (string, error) {
+	path := filepath.Join(p.dir, name)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read %s", path)
+	}
+	value := strings.TrimSpace(string(data))
+	if value == "" && required {
+		return "", errors.Errorf("%s is empty", path)
+	}
+	return value, nil
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	. "github.com/onsi/gomega"
+)
+
+// credentialsOf returns the current credentials of the provider, which must be valid.
+func credentialsOf(t *testing.T, p Provider) *basic.Credentials {
+	t.Helper()
+	credentials, err := p.Credentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return credentials
+}
+
+// writeFiles writes the credentials files in the directory, an empty value removes the file.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, value := range files {
+		path := filepath.Join(dir, name)
+		if value == "" {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(value+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileProviderReload(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{AccessKeyFile: "ak1", SecretKeyFile: "sk1"})
+
+	p, err := NewFileProvider(dir, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	first := credentialsOf(t, p)
+	g.Expect(first.AK).To(Equal("ak1"))
+	g.Expect(first.SK).To(Equal("sk1"))
+	g.Expect(first.ProjectId).To(BeEmpty())
+
+	// unchanged files keep the credentials
+	reloaded, err := p.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reloaded).To(BeFalse())
+	g.Expect(credentialsOf(t, p)).To(BeIdenticalTo(first))
+
+	// a rotated key replaces the credentials
+	writeFiles(t, dir, map[string]string{SecretKeyFile: "sk2", ProjectIDFile: "project"})
+	reloaded, err = p.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reloaded).To(BeTrue())
+	second := credentialsOf(t, p)
+	g.Expect(second.AK).To(Equal("ak1"))
+	g.Expect(second.SK).To(Equal("sk2"))
+	g.Expect(second.ProjectId).To(Equal("project"))
+
+	// invalid files keep the previous credentials
+	writeFiles(t, dir, map[string]string{SecretKeyFile: " "})
+	_, err = p.reload()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(credentialsOf(t, p)).To(BeIdenticalTo(second))
+
+	writeFiles(t, dir, map[string]string{AccessKeyFile: ""})
+	_, err = p.reload()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(credentialsOf(t, p)).To(BeIdenticalTo(second))
+}
+
+func TestNewFileProviderMissingFiles(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{AccessKeyFile: "ak"})
+
+	_, err := NewFileProvider(dir, time.Minute)
+	g.Expect(err).To(MatchError(ContainSubstring(SecretKeyFile)))
+}
+
+// temporaryFiles returns the files of temporary credentials expiring at the time.
+func temporaryFiles(ak string, expiresAt time.Time) map[string]string {
+	return map[string]string{
+		AccessKeyFile:     ak,
+		SecretKeyFile:     "sk",
+		SecurityTokenFile: "token-" + ak,
+		ExpiresAtFile:     expiresAt.Format(time.RFC3339),
+	}
+}
+
+func TestNewExternalTemporaryFileProvider(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name:  "valid credentials",
+			files: temporaryFiles("ak", expiresAt),
+		},
+		{
+			name:    "missing security token",
+			files:   map[string]string{AccessKeyFile: "ak", SecretKeyFile: "sk", ExpiresAtFile: expiresAt.Format(time.RFC3339)},
+			wantErr: SecurityTokenFile,
+		},
+		{
+			name:    "missing expiry",
+			files:   map[string]string{AccessKeyFile: "ak", SecretKeyFile: "sk", SecurityTokenFile: "token"},
+			wantErr: ExpiresAtFile,
+		},
+		{
+			name: "invalid expiry",
+			files: map[string]string{
+				AccessKeyFile:     "ak",
+				SecretKeyFile:     "sk",
+				SecurityTokenFile: "token",
+				ExpiresAtFile:     expiresAt.Format(time.DateTime),
+			},
+			wantErr: "invalid expiry",
+		},
+		{
+			name:    "expired credentials",
+			files:   temporaryFiles("ak", time.Now().Add(-time.Minute)),
+			wantErr: "expired at",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			p, err := NewExternalTemporaryFileProvider(dir, time.Minute)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(credentialsOf(t, p).AK).To(Equal("ak"))
+			g.Expect(credentialsOf(t, p).SecurityToken).To(Equal("token-ak"))
+			g.Expect(p.expiry()).To(BeTemporally("==", expiresAt))
+		})
+	}
+}
+
+func TestExternalTemporaryFileProviderReload(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	firstExpiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	writeFiles(t, dir, temporaryFiles("ak1", firstExpiry))
+
+	p, err := NewExternalTemporaryFileProvider(dir, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	first := credentialsOf(t, p)
+
+	// the credentials rotated by the issuer replace the previous ones with their expiry
+	secondExpiry := firstExpiry.Add(time.Hour)
+	writeFiles(t, dir, temporaryFiles("ak2", secondExpiry))
+	reloaded, err := p.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reloaded).To(BeTrue())
+	second := credentialsOf(t, p)
+	g.Expect(second).NotTo(BeIdenticalTo(first))
+	g.Expect(second.AK).To(Equal("ak2"))
+	g.Expect(second.SecurityToken).To(Equal("token-ak2"))
+	g.Expect(p.expiry()).To(BeTemporally("==", secondExpiry))
+
+	// expired files keep the previous credentials
+	writeFiles(t, dir, temporaryFiles("ak3", time.Now().Add(-time.Minute)))
+	_, err = p.reload()
+	g.Expect(err).To(MatchError(ContainSubstring("expired at")))
+	g.Expect(credentialsOf(t, p)).To(BeIdenticalTo(second))
+	g.Expect(p.expiry()).To(BeTemporally("==", secondExpiry))
+}
+
+func TestExternalTemporaryFileProviderExpiry(t *testing.T) {
+	g := NewWithT(t)
+	dir := t.TempDir()
+	// the expiry has a precision of a second, the credentials are valid for one to two seconds
+	expiresAt := time.Now().Truncate(time.Second).Add(2 * time.Second)
+	writeFiles(t, dir, temporaryFiles("ak", expiresAt))
+
+	p, err := NewExternalTemporaryFileProvider(dir, time.Minute)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(credentialsOf(t, p).AK).To(Equal("ak"))
+
+	// the files are not rotated before their expiry
+	time.Sleep(time.Until(expiresAt))
+	_, err = p.reload()
+	g.Expect(err).To(MatchError(ContainSubstring("expired at")))
+	credentials, err := p.Credentials()
+	g.Expect(err).To(MatchError(ContainSubstring("expired at")))
+	g.Expect(credentials).To(BeNil())
+
+	// rotated files are used again
+	writeFiles(t, dir, temporaryFiles("ak2", time.Now().Add(time.Hour)))
+	reloaded, err := p.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reloaded).To(BeTrue())
+	g.Expect(credentialsOf(t, p).AK).To(Equal("ak2"))
+}
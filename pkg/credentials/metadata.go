@@ -0,0 +1,180 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/metrics"
+)
+
+const (
+	// metadataSecurityKeyURL is the URL of the ECS metadata service returning the temporary credentials
+	// of the agency of the instance.
+	metadataSecurityKeyURL = "http://169.254.169.254/openstack/latest/securitykey"
+
+	// metadataTimeout is the timeout of a request to the ECS metadata service.
+	metadataTimeout = 5 * time.Second
+
+	// metadataRetryInterval is the delay before fetching the credentials again after a failure,
+	// and the minimum delay between two fetches.
+	metadataRetryInterval = 30 * time.Second
+
+	// refreshWindow is how long before their expiry the temporary credentials are renewed.
+	refreshWindow = 10 * time.Minute
+
+	// metadataSource is the source of the ECS metadata credentials reported in the metrics.
+	metadataSource = "metadata"
+)
+
+// metadataSecurityKey is the response of the ECS metadata service.
+type metadataSecurityKey struct {
+	Credential struct {
+		Access        string `json:"access"`
+		Secret        string `json:"secret"`
+		SecurityToken string `json:"securitytoken"`
+		ExpiresAt     string `json:"expires_at"`
+	} `json:"credential"`
+}
+
+// MetadataProvider provides the temporary credentials of the agency of the ECS instance the manager runs on.
+// The credentials are fetched from the ECS metadata service and renewed before their expiry,
+// so no access key has to be stored in the management cluster.
+type MetadataProvider struct {
+	projectID  string
+	httpClient *http.Client
+
+	mu          sync.RWMutex
+	credentials *basic.Credentials
+	expiresAt   time.Time
+}
+
+// NewMetadataProvider returns a provider of the credentials of the ECS agency, which are fetched once before returning.
+// The provider must be added to the manager to renew the credentials. The project of the cluster region is looked up
+// when projectID is empty.
+func NewMetadataProvider(ctx context.Context, projectID string) (*MetadataProvider, error) {
+	p := &MetadataProvider{
+		projectID: projectID,
+		// The metadata service is link-local, it is never reached through a proxy.
+		httpClient: &http.Client{
+			Timeout:   metadataTimeout,
+			Transport: &http.Transport{Proxy: nil},
+		},
+	}
+	if err := p.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Credentials implements Provider, it fails once the credentials expired without being renewed.
+func (p *MetadataProvider) Credentials() (*basic.Credentials, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if err := checkExpiry(p.expiresAt); err != nil {
+		return nil, errors.Wrap(err, "controller credentials from the ECS metadata service")
+	}
+	return p.credentials, nil
+}
+
+// Start implements manager.Runnable, it renews the credentials before their expiry until the context is done.
+func (p *MetadataProvider) Start(ctx context.Context) error {
+	for {
+		p.mu.RLock()
+		delay := time.Until(p.expiresAt) - refreshWindow
+		p.mu.RUnlock()
+
+		// credentials valid for less than the refresh window are not fetched in a loop
+		timer := time.NewTimer(max(delay, metadataRetryInterval))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		if err := p.refresh(ctx); err != nil {
+			klog.Errorf("Failed to renew the controller credentials from the ECS metadata service, retrying in %s: %v",
+				metadataRetryInterval, err)
+			metrics.ObserveControllerCredentialsReloadError()
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(metadataRetryInterval):
+			}
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, the credentials are needed by all the replicas.
+func (p *MetadataProvider) NeedLeaderElection() bool {
+	return false
+}
+
+// refresh fetches new credentials from the ECS metadata service.
+func (p *MetadataProvider) refresh(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataSecurityKeyURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create ECS metadata request")
+	}
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrap(err, "failed to get credentials from the ECS metadata service")
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		// The body is not reported, the status is enough to tell a missing agency from an unreachable service.
+		return errors.Errorf("ECS metadata service returned %s, check that the instance has an agency", response.Status)
+	}
+
+	securityKey := &metadataSecurityKey{}
+	if err := json.NewDecoder(response.Body).Decode(securityKey); err != nil {
+		return errors.Wrap(err, "failed to decode ECS metadata credentials")
+	}
+	expiresAt, err := time.Parse(time.RFC3339, securityKey.Credential.ExpiresAt)
+	if err != nil {
+		return errors.Wrap(err, "invalid expiry of the ECS metadata credentials")
+	}
+
+	credentials, err := basic.NewCredentialsBuilder().
+		WithAk(securityKey.Credential.Access).
+		WithSk(securityKey.Credential.Secret).
+		WithSecurityToken(securityKey.Credential.SecurityToken).
+		WithProjectId(p.projectID).
+		SafeBuild()
+	if err != nil {
+		return errors.Wrap(err, "failed to create credentials from the ECS metadata service")
+	}
+	klog.Infof("Got the controller credentials from the ECS metadata service, credentials expire at %s",
+		expiresAt.Format(time.RFC3339))
+
+	p.mu.Lock()
+	p.credentials = credentials
+	p.expiresAt = expiresAt
+	p.mu.Unlock()
+
+	metrics.SetControllerCredentials(securityKey.Credential.Access, metadataSource, expiresAt)
+	return nil
+}
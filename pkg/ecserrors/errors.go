@@ -1,6 +1,8 @@
 package ecserrors
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/sdkerr"
@@ -8,11 +10,14 @@ import (
 
 const (
 	ErrrorECSNotFound = "Ecs.0114"
+	// ErrorAPIGatewayThrottled is returned by the API gateway when the request rate exceeds the flow control limits.
+	ErrorAPIGatewayThrottled = "APIGW.0308"
 )
 
 // StatusCode returns the HTTP status for a particular error.
 func StatusCode(err error) int {
-	if t, ok := err.(*sdkerr.ServiceResponseError); ok {
+	var t *sdkerr.ServiceResponseError
+	if errors.As(err, &t) {
 		return t.StatusCode
 	}
 
@@ -21,13 +26,48 @@ func StatusCode(err error) int {
 
 // ErrorCode returns the error code for a particular error.
 func ErrorCode(err error) string {
-	if t, ok := err.(*sdkerr.ServiceResponseError); ok {
+	var t *sdkerr.ServiceResponseError
+	if errors.As(err, &t) {
 		return t.ErrorCode
 	}
 
 	return ""
 }
 
+// Describe returns the message of the error followed by its HuaweiCloud error code, if any.
+func Describe(err error) string {
+	if code := ErrorCode(err); code != "" {
+		return fmt.Sprintf("%v (error code: %s)", err, code)
+	}
+	return err.Error()
+}
+
+// IsDependencyInUse returns true if the resource could not be deleted because it is still in use,
+// e.g. a subnet with ports of machines or a security group referenced by another resource.
+func IsDependencyInUse(err error) bool {
+	var responseErr *sdkerr.ServiceResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusConflict
+}
+
+// IsThrottled returns true if the request was rejected because the API rate limit was exceeded.
+func IsThrottled(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests || ErrorCode(err) == ErrorAPIGatewayThrottled
+}
+
+// IsRetryable returns true if the request failed with a throttling or transient server error
+// and can be sent again. Internal server errors and gateway timeouts are not retried as the request
+// may have been processed, e.g. a resource created twice.
+func IsRetryable(err error) bool {
+	if IsThrottled(err) {
+		return true
+	}
+	switch StatusCode(err) {
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
 func IsNotFound(err error) bool {
 	if StatusCode(err) == http.StatusNotFound && ErrorCode(err) == ErrrorECSNotFound {
 		return true
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	accessKeyIDLabel = "access_key_id"
+	sourceLabel      = "source"
+)
+
+var (
+	controllerCredentialsInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: "controller_credentials",
+		Name:      "info",
+		Help:      "Access key ID and source of the credentials currently used by the controller, the value is always 1.",
+	}, []string{accessKeyIDLabel, sourceLabel})
+
+	controllerCredentialsLastReloadSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: "controller_credentials",
+		Name:      "last_reload_timestamp_seconds",
+		Help:      "Time of the last change of the controller credentials.",
+	})
+
+	controllerCredentialsExpirySeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: metricNamespace,
+		Subsystem: "controller_credentials",
+		Name:      "expiry_timestamp_seconds",
+		Help:      "Expiry time of the temporary credentials of the controller, 0 for permanent credentials.",
+	})
+
+	controllerCredentialsReloadErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: "controller_credentials",
+		Name:      "reload_errors_total",
+		Help:      "Total number of failed reloads or refreshes of the controller credentials, the previous credentials are kept in use.",
+	})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(controllerCredentialsInfo, controllerCredentialsLastReloadSeconds,
+		controllerCredentialsExpirySeconds, controllerCredentialsReloadErrorsTotal)
+}
+
+// SetControllerCredentials reports the access key ID of the credentials now used by the controller,
+// and their expiry if they are temporary. Only the key ID is exported, never the secret key or the security token.
+func SetControllerCredentials(accessKeyID, source string, expiresAt time.Time) {
+	controllerCredentialsInfo.Reset()
+	controllerCredentialsInfo.WithLabelValues(accessKeyID, source).Set(1)
+	controllerCredentialsLastReloadSeconds.Set(float64(time.Now().Unix()))
+
+	expiry := 0.0
+	if !expiresAt.IsZero() {
+		expiry = float64(expiresAt.Unix())
+	}
+	controllerCredentialsExpirySeconds.Set(expiry)
+}
+
+// ObserveControllerCredentialsReloadError records a failed reload or refresh of the controller credentials.
+func ObserveControllerCredentialsReloadError() {
+	controllerCredentialsReloadErrorsTotal.Inc()
+}
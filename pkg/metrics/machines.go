@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+)
+
+// unknownInstanceState is reported for the machines whose instance state has not been observed yet.
+const unknownInstanceState = "unknown"
+
+var machinesDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(metricNamespace, "", "machines"),
+	"Number of HuaweiCloudMachines managed by the provider by ECS instance state.",
+	[]string{stateLabel}, nil,
+)
+
+// machineCollector counts the HuaweiCloudMachines by instance state when the metrics are scraped.
+type machineCollector struct {
+	reader client.Reader
+}
+
+// RegisterMachineCollector registers the gauges of the managed machines per instance state.
+// The reader should be backed by the manager cache, it is read on every scrape.
+func RegisterMachineCollector(reader client.Reader) error {
+	return ctrlmetrics.Registry.Register(&machineCollector{reader: reader})
+}
+
+// Describe implements prometheus.Collector.
+func (c *machineCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- machinesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *machineCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	machines := &infrav1alpha1.HuaweiCloudMachineList{}
+	if err := c.reader.List(ctx, machines); err != nil {
+		klog.Errorf("Failed to list HuaweiCloudMachines for metrics: %v", err)
+		return
+	}
+
+	counts := map[string]int{}
+	for _, machine := range machines.Items {
+		state := unknownInstanceState
+		if machine.Status.InstanceState != nil {
+			state = string(*machine.Status.InstanceState)
+		}
+		counts[state]++
+	}
+	for state, count := range counts {
+		ch <- prometheus.MustNewConstMetric(machinesDesc, prometheus.GaugeValue, float64(count), state)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics provides the Prometheus metrics of the HuaweiCloud API calls and the managed machines.
+// The metrics are registered with the controller-runtime metrics registry, which is served by the manager.
+package metrics
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
+)
+
+const (
+	metricNamespace = "capi_huaweicloud"
+
+	serviceLabel    = "service"
+	operationLabel  = "operation"
+	statusCodeLabel = "status_code"
+	errorCodeLabel  = "error_code"
+	stateLabel      = "state"
+)
+
+var (
+	apiRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Total number of HuaweiCloud API requests by service, operation, HTTP status and error code.",
+	}, []string{serviceLabel, operationLabel, statusCodeLabel, errorCodeLabel})
+
+	apiRequestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricNamespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the HuaweiCloud API requests by service, operation, HTTP status and error code.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{serviceLabel, operationLabel, statusCodeLabel, errorCodeLabel})
+
+	apiRequestRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: "api",
+		Name:      "request_retries_total",
+		Help:      "Total number of HuaweiCloud API requests retried after throttling or a transient error.",
+	}, []string{serviceLabel, operationLabel})
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(apiRequestsTotal, apiRequestDurationSeconds, apiRequestRetriesTotal)
+}
+
+// Observe calls the HuaweiCloud API operation of the service and records the request metrics.
+func Observe[Req, Resp any](service, operation string, call func(Req) (Resp, error), request Req) (Resp, error) {
+	start := time.Now()
+	response, err := call(request)
+
+	statusCode, errorCode := responseStatusCode(response), ""
+	if err != nil {
+		statusCode, errorCode = ecserrors.StatusCode(err), ecserrors.ErrorCode(err)
+		// a request which did not get a response, e.g. because of a connection error, has status 0
+		statusCode = max(statusCode, 0)
+	}
+	labels := prometheus.Labels{
+		serviceLabel:    service,
+		operationLabel:  operation,
+		statusCodeLabel: strconv.Itoa(statusCode),
+		errorCodeLabel:  errorCode,
+	}
+	apiRequestsTotal.With(labels).Inc()
+	apiRequestDurationSeconds.With(labels).Observe(time.Since(start).Seconds())
+
+	return response, err
+}
+
+// ObserveRetry records the retry of a HuaweiCloud API request.
+func ObserveRetry(service, operation string) {
+	apiRequestRetriesTotal.WithLabelValues(service, operation).Inc()
+}
+
+// responseStatusCode returns the HTTP status of the SDK response, all responses have a HttpStatusCode field.
+func responseStatusCode(response any) int {
+	v := reflect.ValueOf(response)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return 0
+	}
+	field := v.Elem().FieldByName("HttpStatusCode")
+	if !field.IsValid() || field.Kind() != reflect.Int {
+		return 0
+	}
+	return int(field.Int())
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/sdkerr"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testResponse struct {
+	HttpStatusCode int `json:"-"`
+}
+
+func TestObserveStatusCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		response   *testResponse
+		err        error
+		statusCode string
+		errorCode  string
+	}{
+		{
+			name:       "success",
+			response:   &testResponse{HttpStatusCode: http.StatusOK},
+			statusCode: "200",
+		},
+		{
+			name:       "HuaweiCloud error",
+			err:        &sdkerr.ServiceResponseError{StatusCode: http.StatusNotFound, ErrorCode: "Ecs.0114"},
+			statusCode: "404",
+			errorCode:  "Ecs.0114",
+		},
+		{
+			name:       "connection error",
+			err:        errors.New("connection refused"),
+			statusCode: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			call := func(string) (*testResponse, error) { return tt.response, tt.err }
+
+			_, err := Observe("test", tt.name, call, "")
+			if tt.err != nil {
+				g.Expect(err).To(MatchError(tt.err))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			counter := apiRequestsTotal.WithLabelValues("test", tt.name, tt.statusCode, tt.errorCode)
+			g.Expect(testutil.ToFloat64(counter)).To(Equal(1.0))
+		})
+	}
+}
@@ -17,25 +17,10 @@ limitations under the License.
 package scope
 
 import (
-	ecsiface "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2"
-	ecsRegion "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2/region"
-	"k8s.io/klog/v2"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/clients"
 )
 
-func NewECSClient(scope ECSScope) (*ecsiface.EcsClient, error) {
-	region, err := ecsRegion.SafeValueOf(scope.Region())
-	if err != nil {
-		klog.Errorf("Failed to get region: %v", err)
-		return nil, err
-	}
-	ecsHcClient, err := ecsiface.EcsClientBuilder().
-		WithRegion(region).
-		WithCredential(scope.Credential()).
-		SafeBuild()
-	if err != nil {
-		return nil, err
-	}
-
-	ecsClient := ecsiface.NewEcsClient(ecsHcClient)
-	return ecsClient, nil
+// NewECSClient returns the cached ECS client of the scope region and credential.
+func NewECSClient(scope ECSScope) (*clients.ECSClient, error) {
+	return clients.NewECSClient(scope.Region(), scope.Credential())
 }
@@ -18,13 +18,18 @@ package scope
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/credentials"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -32,18 +37,21 @@ import (
 )
 
 // ClusterScopeParams defines the input parameters used to create a new Scope.
+// Credentials provides the controller credentials, used unless the HuaweiCloudCluster has an identityRef.
 type ClusterScopeParams struct {
 	Client      client.Client
 	Logger      *logr.Logger
 	Cluster     *clusterv1.Cluster
 	HCCluster   *infrav1alpha1.HuaweiCloudCluster
-	Credentials *basic.Credentials
+	Credentials credentials.Provider
+	Recorder    record.EventRecorder
 }
 
 // ClusterScope defines the basic context for an actuator to operate upon.
 type ClusterScope struct {
 	client      client.Client
 	patchHelper *patch.Helper
+	recorder    record.EventRecorder
 	Logger      *logr.Logger
 	Cluster     *clusterv1.Cluster
 	HCCluster   *infrav1alpha1.HuaweiCloudCluster
@@ -52,7 +60,7 @@ type ClusterScope struct {
 
 // NewClusterScope creates a new Scope from the supplied parameters.
 // This is meant to be called for each reconcile iteration.
-func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
+func NewClusterScope(ctx context.Context, params ClusterScopeParams) (*ClusterScope, error) {
 	if params.Cluster == nil {
 		return nil, errors.New("failed to generate new scope from nil Cluster")
 	}
@@ -65,11 +73,15 @@ func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
 	}
 
 	clusterScope := &ClusterScope{
-		Logger:      params.Logger,
-		client:      params.Client,
-		Cluster:     params.Cluster,
-		HCCluster:   params.HCCluster,
-		Credentials: params.Credentials,
+		Logger:    params.Logger,
+		client:    params.Client,
+		Cluster:   params.Cluster,
+		HCCluster: params.HCCluster,
+		recorder:  params.Recorder,
+	}
+	if clusterScope.recorder == nil {
+		// events are dropped without a recorder
+		clusterScope.recorder = &record.FakeRecorder{}
 	}
 
 	helper, err := patch.NewHelper(params.HCCluster, params.Client)
@@ -78,6 +90,14 @@ func NewClusterScope(params ClusterScopeParams) (*ClusterScope, error) {
 	}
 	clusterScope.patchHelper = helper
 
+	// The credentials of the cluster identity take precedence over the controller credentials.
+	resolved, err := clusterScope.resolveCredentials(ctx, params.Credentials)
+	if err != nil {
+		clusterScope.Warningf(err, "FailedResolveIdentity", "Failed to resolve the credentials of the cluster")
+		return nil, errors.Wrap(err, "failed to resolve credentials")
+	}
+	clusterScope.Credentials = resolved
+
 	return clusterScope, nil
 }
 
@@ -111,6 +131,29 @@ func (s *ClusterScope) Subnets() infrav1alpha1.Subnets {
 	return s.HCCluster.Spec.NetworkSpec.Subnets
 }
 
+// CNIIngressRules returns the CNI spec ingress rules.
+func (s *ClusterScope) CNIIngressRules() infrav1alpha1.CNIIngressRules {
+	if s.HCCluster.Spec.NetworkSpec.CNI != nil {
+		return s.HCCluster.Spec.NetworkSpec.CNI.CNIIngressRules
+	}
+	return infrav1alpha1.CNIIngressRules{}
+}
+
+// APIServerAllowedCIDRs returns the CIDR blocks allowed to access the API server.
+func (s *ClusterScope) APIServerAllowedCIDRs() []string {
+	return s.HCCluster.Spec.APIServerAllowedCIDRs
+}
+
+// SSH returns the SSH access configuration of the cluster machines.
+func (s *ClusterScope) SSH() *infrav1alpha1.SSHSpec {
+	return s.HCCluster.Spec.SSH
+}
+
+// AdditionalIngressRules returns the user-defined ingress rules for the given security group role.
+func (s *ClusterScope) AdditionalIngressRules(role infrav1alpha1.SecurityGroupRole) infrav1alpha1.IngressRules {
+	return s.HCCluster.Spec.AdditionalIngressRules[role]
+}
+
 // SetSubnets updates the clusters subnets.
 func (s *ClusterScope) SetSubnets(subnets infrav1alpha1.Subnets) {
 	s.HCCluster.Spec.NetworkSpec.Subnets = subnets
@@ -121,6 +164,19 @@ func (s *ClusterScope) SetNatGatewaysIPs(ips []string) {
 	s.HCCluster.Status.Network.NatGatewaysIPs = ips
 }
 
+// SetNatGateways sets the Nat Gateways of the cluster.
+func (s *ClusterScope) SetNatGateways(natGateways []infrav1alpha1.NatGateway) {
+	s.HCCluster.Status.Network.NatGateways = natGateways
+}
+
+// NatGateway returns the cluster Nat Gateway configuration.
+func (s *ClusterScope) NatGateway() *infrav1alpha1.NatGatewaySpec {
+	if s.HCCluster.Spec.NetworkSpec.NatGateway == nil {
+		return &infrav1alpha1.NatGatewaySpec{}
+	}
+	return s.HCCluster.Spec.NetworkSpec.NatGateway
+}
+
 // Region returns the cluster region.
 func (s *ClusterScope) Region() string {
 	return s.HCCluster.Spec.Region
@@ -136,6 +192,35 @@ func (s *ClusterScope) SetSecurityGroups(sg map[infrav1alpha1.SecurityGroupRole]
 	s.HCCluster.Status.Network.SecurityGroups = sg
 }
 
+// ControlPlaneLoadBalancer returns the cluster control plane load balancer configuration.
+func (s *ClusterScope) ControlPlaneLoadBalancer() *infrav1alpha1.HuaweiCloudLoadBalancerSpec {
+	if s.HCCluster.Spec.ControlPlaneLoadBalancer == nil {
+		return &infrav1alpha1.HuaweiCloudLoadBalancerSpec{}
+	}
+	return s.HCCluster.Spec.ControlPlaneLoadBalancer
+}
+
+// SecondaryControlPlaneLoadBalancer returns the secondary internal control plane load balancer spec, if any.
+func (s *ClusterScope) SecondaryControlPlaneLoadBalancer() *infrav1alpha1.HuaweiCloudLoadBalancerSpec {
+	return s.HCCluster.Spec.SecondaryControlPlaneLoadBalancer
+}
+
+// APIServerPort returns the port of the control plane load balancer API server listener.
+func (s *ClusterScope) APIServerPort() int32 {
+	if s.ControlPlaneLoadBalancer().Port == 0 {
+		return infrav1alpha1.DefaultAPIServerPort
+	}
+	return s.ControlPlaneLoadBalancer().Port
+}
+
+// APIServerBackendPort returns the port the API servers listen on.
+func (s *ClusterScope) APIServerBackendPort() int32 {
+	if s.ControlPlaneLoadBalancer().BackendPort == 0 {
+		return infrav1alpha1.DefaultAPIServerPort
+	}
+	return s.ControlPlaneLoadBalancer().BackendPort
+}
+
 // ELB returns the cluster ELB.
 func (s *ClusterScope) ELB() infrav1alpha1.LoadBalancer {
 	return s.HCCluster.Status.Network.ELB
@@ -146,6 +231,37 @@ func (s *ClusterScope) SetELB(elb infrav1alpha1.LoadBalancer) {
 	s.HCCluster.Status.Network.ELB = elb
 }
 
+// SecondaryELB returns the cluster secondary internal ELB.
+func (s *ClusterScope) SecondaryELB() infrav1alpha1.LoadBalancer {
+	return s.HCCluster.Status.Network.SecondaryELB
+}
+
+// SetSecondaryELB updates the cluster secondary internal ELB.
+func (s *ClusterScope) SetSecondaryELB(elb infrav1alpha1.LoadBalancer) {
+	s.HCCluster.Status.Network.SecondaryELB = elb
+}
+
+// SetInternalControlPlaneEndpoint updates the endpoint of the secondary internal control plane load balancer.
+func (s *ClusterScope) SetInternalControlPlaneEndpoint(endpoint clusterv1.APIEndpoint) {
+	s.HCCluster.Status.InternalControlPlaneEndpoint = endpoint
+}
+
+// Recorder returns the event recorder of the controller.
+func (s *ClusterScope) Recorder() record.EventRecorder {
+	return s.recorder
+}
+
+// Eventf records a Normal event on the HuaweiCloudCluster.
+func (s *ClusterScope) Eventf(reason, messageFmt string, args ...interface{}) {
+	s.recorder.Eventf(s.HCCluster, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warningf records a Warning event on the HuaweiCloudCluster, the error is appended
+// to the message with its HuaweiCloud error code.
+func (s *ClusterScope) Warningf(err error, reason, messageFmt string, args ...interface{}) {
+	s.recorder.Eventf(s.HCCluster, corev1.EventTypeWarning, reason, "%s: %s", fmt.Sprintf(messageFmt, args...), ecserrors.Describe(err))
+}
+
 // PatchObject persists the cluster configuration and status.
 func (s *ClusterScope) PatchObject() error {
 	applicableConditions := []clusterv1.ConditionType{
@@ -170,6 +286,8 @@ func (s *ClusterScope) PatchObject() error {
 			infrav1alpha1.SubnetsReadyCondition,
 			infrav1alpha1.ClusterSecurityGroupsReadyCondition,
 			infrav1alpha1.NatGatewaysReadyCondition,
+			infrav1alpha1.LoadBalancerMembersHealthyCondition,
+			infrav1alpha1.InfrastructureDeletedCondition,
 		}})
 }
 
@@ -17,6 +17,8 @@ limitations under the License.
 package scope
 
 import (
+	"k8s.io/client-go/tools/record"
+
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/basic"
 )
@@ -48,4 +50,7 @@ type ECSScope interface {
 
 	// ImageLookupBaseOS returns the base operating system name to use when looking up AMIs
 	ImageLookupBaseOS() string
+
+	// Recorder returns the event recorder of the controller.
+	Recorder() record.EventRecorder
 }
@@ -0,0 +1,271 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	iammodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/iam/v3/model"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/clients"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/credentials"
+)
+
+const (
+	// podNamespaceEnv is the environment variable holding the namespace of the controller.
+	podNamespaceEnv = "POD_NAMESPACE"
+
+	// defaultControllerNamespace is the namespace of the controller when POD_NAMESPACE is not set.
+	defaultControllerNamespace = "caph-system"
+
+	// agencyCredentialsRefreshWindow is how long before their expiry the agency credentials are renewed.
+	agencyCredentialsRefreshWindow = 10 * time.Minute
+)
+
+// ControllerNamespace returns the namespace of the controller, which holds the Secrets of the static identities.
+func ControllerNamespace() string {
+	if ns := os.Getenv(podNamespaceEnv); ns != "" {
+		return ns
+	}
+	return defaultControllerNamespace
+}
+
+// agencyCredentials are the temporary credentials obtained by assuming an agency.
+type agencyCredentials struct {
+	resourceVersion string
+	credentials     *basic.Credentials
+	expiresAt       time.Time
+}
+
+var (
+	agencyCredentialsMu sync.Mutex
+	// agencyCredentialsCache holds the credentials of the agency identities by name,
+	// so that the agency is only assumed again when the credentials are about to expire.
+	agencyCredentialsCache = map[string]agencyCredentials{}
+)
+
+// resolveCredentials returns the credentials of the identity referenced by the HuaweiCloudCluster,
+// or the controller credentials when the cluster has no identity.
+func (s *ClusterScope) resolveCredentials(ctx context.Context, provider credentials.Provider) (*basic.Credentials, error) {
+	ref := s.HCCluster.Spec.IdentityRef
+	if ref == nil {
+		controllerCredentials, err := controllerCredentials(provider)
+		if err != nil {
+			return nil, err
+		}
+		if controllerCredentials == nil {
+			return nil, errors.New("the cluster has no identityRef and the controller has no credentials")
+		}
+		return controllerCredentials, nil
+	}
+
+	switch ref.Kind {
+	case infrav1alpha1.ClusterStaticIdentityKind:
+		return s.staticIdentityCredentials(ctx, ref.Name)
+	case infrav1alpha1.ClusterAgencyIdentityKind:
+		return s.agencyIdentityCredentials(ctx, ref.Name, provider)
+	default:
+		return nil, errors.Errorf("unsupported identity kind %q", ref.Kind)
+	}
+}
+
+// controllerCredentials returns the current controller credentials, or nil if the controller has no credentials.
+// They are read on each reconcile, so that the reloaded credentials are used.
+func controllerCredentials(provider credentials.Provider) (*basic.Credentials, error) {
+	if provider == nil {
+		return nil, nil
+	}
+	return provider.Credentials()
+}
+
+// staticIdentityCredentials returns the credentials stored in the Secret of the HuaweiCloudClusterStaticIdentity.
+func (s *ClusterScope) staticIdentityCredentials(ctx context.Context, name string) (*basic.Credentials, error) {
+	identity := &infrav1alpha1.HuaweiCloudClusterStaticIdentity{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: name}, identity); err != nil {
+		return nil, errors.Wrapf(err, "failed to get HuaweiCloudClusterStaticIdentity %s", name)
+	}
+	if err := s.checkIdentityAllowed(ctx, identity.Spec.AllowedNamespaces, infrav1alpha1.ClusterStaticIdentityKind, name); err != nil {
+		return nil, err
+	}
+
+	secret := &corev1.Secret{}
+	secretKey := client.ObjectKey{Namespace: ControllerNamespace(), Name: identity.Spec.SecretRef}
+	if err := s.client.Get(ctx, secretKey, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to get Secret %s of HuaweiCloudClusterStaticIdentity %s", secretKey, name)
+	}
+	ak := string(secret.Data[infrav1alpha1.IdentitySecretAccessKey])
+	sk := string(secret.Data[infrav1alpha1.IdentitySecretSecretKey])
+	if ak == "" || sk == "" {
+		return nil, errors.Errorf("Secret %s of HuaweiCloudClusterStaticIdentity %s must have the %s and %s keys",
+			secretKey, name, infrav1alpha1.IdentitySecretAccessKey, infrav1alpha1.IdentitySecretSecretKey)
+	}
+
+	credentials, err := basic.NewCredentialsBuilder().
+		WithAk(ak).
+		WithSk(sk).
+		WithProjectId(string(secret.Data[infrav1alpha1.IdentitySecretProjectID])).
+		SafeBuild()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create credentials of HuaweiCloudClusterStaticIdentity %s", name)
+	}
+	return credentials, nil
+}
+
+// agencyIdentityCredentials returns the temporary credentials obtained by assuming the agency
+// of the HuaweiCloudClusterAgencyIdentity.
+func (s *ClusterScope) agencyIdentityCredentials(ctx context.Context, name string, provider credentials.Provider) (*basic.Credentials, error) {
+	identity := &infrav1alpha1.HuaweiCloudClusterAgencyIdentity{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: name}, identity); err != nil {
+		return nil, errors.Wrapf(err, "failed to get HuaweiCloudClusterAgencyIdentity %s", name)
+	}
+	if err := s.checkIdentityAllowed(ctx, identity.Spec.AllowedNamespaces, infrav1alpha1.ClusterAgencyIdentityKind, name); err != nil {
+		return nil, err
+	}
+
+	agencyCredentialsMu.Lock()
+	defer agencyCredentialsMu.Unlock()
+
+	cached, ok := agencyCredentialsCache[name]
+	if ok && cached.resourceVersion == identity.ResourceVersion && time.Until(cached.expiresAt) > agencyCredentialsRefreshWindow {
+		return cached.credentials, nil
+	}
+
+	// The agency is assumed with the static source identity, which must also allow the namespace
+	// of the cluster, or with the controller credentials.
+	var sourceCredentials *basic.Credentials
+	var err error
+	if ref := identity.Spec.SourceIdentityRef; ref != nil {
+		if ref.Kind != infrav1alpha1.ClusterStaticIdentityKind {
+			return nil, errors.Errorf("source identity of HuaweiCloudClusterAgencyIdentity %s must be a %s", name, infrav1alpha1.ClusterStaticIdentityKind)
+		}
+		if sourceCredentials, err = s.staticIdentityCredentials(ctx, ref.Name); err != nil {
+			return nil, err
+		}
+	} else if sourceCredentials, err = controllerCredentials(provider); err != nil {
+		return nil, err
+	}
+	if sourceCredentials == nil {
+		return nil, errors.Errorf("HuaweiCloudClusterAgencyIdentity %s has no source identity and the controller has no credentials", name)
+	}
+
+	iamClient, err := clients.NewIAMClient(s.Region(), sourceCredentials)
+	if err != nil {
+		return nil, err
+	}
+	assumeRole := &iammodel.IdentityAssumerole{
+		AgencyName: identity.Spec.AgencyName,
+	}
+	if identity.Spec.DomainID != "" {
+		assumeRole.DomainId = ptr.To(identity.Spec.DomainID)
+	}
+	if identity.Spec.DomainName != "" {
+		assumeRole.DomainName = ptr.To(identity.Spec.DomainName)
+	}
+	if identity.Spec.DurationSeconds != 0 {
+		assumeRole.DurationSeconds = ptr.To(identity.Spec.DurationSeconds)
+	}
+	response, err := iamClient.CreateTemporaryAccessKeyByAgency(ctx, &iammodel.CreateTemporaryAccessKeyByAgencyRequest{
+		Body: &iammodel.CreateTemporaryAccessKeyByAgencyRequestBody{
+			Auth: &iammodel.AgencyAuth{
+				Identity: &iammodel.AgencyAuthIdentity{
+					Methods:    []iammodel.AgencyAuthIdentityMethods{iammodel.GetAgencyAuthIdentityMethodsEnum().ASSUME_ROLE},
+					AssumeRole: assumeRole,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to assume agency %s of HuaweiCloudClusterAgencyIdentity %s", identity.Spec.AgencyName, name)
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, response.Credential.ExpiresAt)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid expiry of the credentials of HuaweiCloudClusterAgencyIdentity %s", name)
+	}
+	credentials, err := basic.NewCredentialsBuilder().
+		WithAk(response.Credential.Access).
+		WithSk(response.Credential.Secret).
+		WithSecurityToken(response.Credential.Securitytoken).
+		WithProjectId(identity.Spec.ProjectID).
+		SafeBuild()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create credentials of HuaweiCloudClusterAgencyIdentity %s", name)
+	}
+	klog.Infof("Assumed agency %s of HuaweiCloudClusterAgencyIdentity %s, credentials expire at %s",
+		identity.Spec.AgencyName, name, expiresAt.Format(time.RFC3339))
+
+	agencyCredentialsCache[name] = agencyCredentials{
+		resourceVersion: identity.ResourceVersion,
+		credentials:     credentials,
+		expiresAt:       expiresAt,
+	}
+	return credentials, nil
+}
+
+// checkIdentityAllowed returns an error if the namespace of the HuaweiCloudCluster is not allowed to use the identity.
+func (s *ClusterScope) checkIdentityAllowed(ctx context.Context, allowed *infrav1alpha1.AllowedNamespaces, kind infrav1alpha1.HuaweiCloudIdentityKind, name string) error {
+	namespace := s.HCCluster.Namespace
+	ok, err := s.isNamespaceAllowed(ctx, allowed, namespace)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("namespace %s is not allowed to use %s %s", namespace, kind, name)
+	}
+	return nil
+}
+
+// isNamespaceAllowed returns true if the namespace is in the list or matches the selector of the allowed namespaces.
+// No namespace is allowed when allowed is nil, and all the namespaces are allowed when it is empty.
+func (s *ClusterScope) isNamespaceAllowed(ctx context.Context, allowed *infrav1alpha1.AllowedNamespaces, namespace string) (bool, error) {
+	if allowed == nil {
+		return false, nil
+	}
+	if reflect.DeepEqual(*allowed, infrav1alpha1.AllowedNamespaces{}) {
+		return true, nil
+	}
+	if slices.Contains(allowed.NamespaceList, namespace) {
+		return true, nil
+	}
+	if reflect.DeepEqual(allowed.Selector, metav1.LabelSelector{}) {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&allowed.Selector)
+	if err != nil {
+		return false, errors.Wrap(err, "invalid allowed namespaces selector")
+	}
+	ns := &corev1.Namespace{}
+	if err := s.client.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, errors.Wrapf(err, "failed to get namespace %s", namespace)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
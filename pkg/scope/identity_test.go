@@ -0,0 +1,177 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scope
+
+import (
+	"context"
+	"testing"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/core/auth/basic"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+)
+
+// expiredProvider provides controller credentials which expired.
+type expiredProvider struct{}
+
+func (expiredProvider) Credentials() (*basic.Credentials, error) {
+	return nil, errors.New("the credentials expired at 2025-01-01T00:00:00Z")
+}
+
+func TestIsNamespaceAllowed(t *testing.T) {
+	s := &ClusterScope{
+		client: fake.NewClientBuilder().WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "b"}}},
+		).Build(),
+	}
+	teamA := metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+
+	tests := []struct {
+		name      string
+		allowed   *infrav1alpha1.AllowedNamespaces
+		namespace string
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name:      "nil allows no namespace",
+			namespace: "team-a",
+			want:      false,
+		},
+		{
+			name:      "empty allows all the namespaces",
+			allowed:   &infrav1alpha1.AllowedNamespaces{},
+			namespace: "team-a",
+			want:      true,
+		},
+		{
+			name:      "namespace in the list",
+			allowed:   &infrav1alpha1.AllowedNamespaces{NamespaceList: []string{"team-b", "team-a"}},
+			namespace: "team-a",
+			want:      true,
+		},
+		{
+			name:      "namespace not in the list",
+			allowed:   &infrav1alpha1.AllowedNamespaces{NamespaceList: []string{"team-b"}},
+			namespace: "team-a",
+			want:      false,
+		},
+		{
+			name:      "namespace matching the selector",
+			allowed:   &infrav1alpha1.AllowedNamespaces{Selector: teamA},
+			namespace: "team-a",
+			want:      true,
+		},
+		{
+			name:      "namespace not matching the selector",
+			allowed:   &infrav1alpha1.AllowedNamespaces{Selector: teamA},
+			namespace: "team-b",
+			want:      false,
+		},
+		{
+			name:      "namespace in the list but not matching the selector",
+			allowed:   &infrav1alpha1.AllowedNamespaces{NamespaceList: []string{"team-b"}, Selector: teamA},
+			namespace: "team-b",
+			want:      true,
+		},
+		{
+			name:      "missing namespace with a selector",
+			allowed:   &infrav1alpha1.AllowedNamespaces{Selector: teamA},
+			namespace: "team-c",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			ok, err := s.isNamespaceAllowed(context.Background(), tt.allowed, tt.namespace)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(ok).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestResolveCredentialsExpiredControllerCredentials(t *testing.T) {
+	g := NewWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(infrav1alpha1.AddToScheme(scheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&infrav1alpha1.HuaweiCloudClusterStaticIdentity{
+			ObjectMeta: metav1.ObjectMeta{Name: "static"},
+			Spec: infrav1alpha1.HuaweiCloudClusterStaticIdentitySpec{
+				HuaweiCloudClusterIdentitySpec: infrav1alpha1.HuaweiCloudClusterIdentitySpec{
+					AllowedNamespaces: &infrav1alpha1.AllowedNamespaces{},
+				},
+				SecretRef: "static-credentials",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "static-credentials", Namespace: ControllerNamespace()},
+			Data: map[string][]byte{
+				infrav1alpha1.IdentitySecretAccessKey: []byte("ak"),
+				infrav1alpha1.IdentitySecretSecretKey: []byte("sk"),
+			},
+		},
+	).Build()
+
+	tests := []struct {
+		name        string
+		identityRef *infrav1alpha1.HuaweiCloudIdentityReference
+		wantErr     bool
+	}{
+		{
+			name:    "cluster using the controller credentials",
+			wantErr: true,
+		},
+		{
+			name:        "cluster using a static identity",
+			identityRef: &infrav1alpha1.HuaweiCloudIdentityReference{Kind: infrav1alpha1.ClusterStaticIdentityKind, Name: "static"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &ClusterScope{
+				client: c,
+				HCCluster: &infrav1alpha1.HuaweiCloudCluster{
+					ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+					Spec:       infrav1alpha1.HuaweiCloudClusterSpec{IdentityRef: tt.identityRef},
+				},
+			}
+			credentials, err := s.resolveCredentials(context.Background(), expiredProvider{})
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ContainSubstring("expired")))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(credentials.AK).To(Equal("ak"))
+		})
+	}
+}
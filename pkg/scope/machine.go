@@ -2,12 +2,15 @@ package scope
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infrav1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
@@ -78,6 +81,17 @@ type MachineScope struct {
 	HCMachine    *infrav1.HuaweiCloudMachine
 }
 
+// Eventf records a Normal event on the HuaweiCloudMachine.
+func (m *MachineScope) Eventf(reason, messageFmt string, args ...interface{}) {
+	m.InfraCluster.Recorder().Eventf(m.HCMachine, corev1.EventTypeNormal, reason, messageFmt, args...)
+}
+
+// Warningf records a Warning event on the HuaweiCloudMachine, the error is appended
+// to the message with its HuaweiCloud error code.
+func (m *MachineScope) Warningf(err error, reason, messageFmt string, args ...interface{}) {
+	m.InfraCluster.Recorder().Eventf(m.HCMachine, corev1.EventTypeWarning, reason, "%s: %s", fmt.Sprintf(messageFmt, args...), ecserrors.Describe(err))
+}
+
 // Name returns the HuaweiCloudMachine name.
 func (m *MachineScope) Name() string {
 	return m.HCMachine.Name
@@ -139,6 +153,11 @@ func (m *MachineScope) SetInstanceState(v infrav1.InstanceState) {
 	m.HCMachine.Status.InstanceState = &v
 }
 
+// SetAddresses sets the HuaweiCloudMachine address status.
+func (m *MachineScope) SetAddresses(addrs []clusterv1.MachineAddress) {
+	m.HCMachine.Status.Addresses = addrs
+}
+
 // SetReady sets the HuaweiCloudMachine Ready Status.
 func (m *MachineScope) SetReady() {
 	m.HCMachine.Status.Ready = true
@@ -169,9 +188,9 @@ func (m *MachineScope) PatchObject() error {
 		infrav1.SecurityGroupsReadyCondition,
 	}
 
-	// if m.IsControlPlane() {
-	// 	applicableConditions = append(applicableConditions, infrav1.ELBAttachedCondition)
-	// }
+	if m.IsControlPlane() {
+		applicableConditions = append(applicableConditions, infrav1.ELBAttachedCondition)
+	}
 
 	conditions.SetSummary(m.HCMachine,
 		conditions.WithConditions(applicableConditions...),
@@ -186,7 +205,7 @@ func (m *MachineScope) PatchObject() error {
 			clusterv1.ReadyCondition,
 			infrav1.InstanceReadyCondition,
 			infrav1.SecurityGroupsReadyCondition,
-			// infrav1.ELBAttachedCondition,
+			infrav1.ELBAttachedCondition,
 		}})
 }
 
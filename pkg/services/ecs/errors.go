@@ -8,4 +8,7 @@ var (
 
 	// ErrShowInstance defines an error for when ECS SDK returns error when showing instances.
 	ErrShowInstance = errors.New("failed to show instance by id")
+
+	// ErrDeleteJobFailed defines an error for when the ECS job deleting an instance failed.
+	ErrDeleteJobFailed = errors.New("instance deletion job failed")
 )
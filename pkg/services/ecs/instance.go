@@ -17,6 +17,7 @@ limitations under the License.
 package ecs
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
@@ -29,13 +30,14 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	infrav1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
 )
 
-func (s *Service) findSubnet(scope *scope.MachineScope) (string, error) {
+func (s *Service) findSubnet(ctx context.Context, scope *scope.MachineScope) (string, error) {
 	// Check Machine.Spec.FailureDomain first
 	// as it's used by KubeadmControlPlane to spread machines across failure domains.
 	failureDomain := scope.Machine.Spec.FailureDomain
@@ -48,7 +50,7 @@ func (s *Service) findSubnet(scope *scope.MachineScope) (string, error) {
 	case scope.HCMachine.Spec.Subnet != nil && scope.HCMachine.Spec.Subnet.ID != nil:
 		var filtered []*vpcModel.Subnet
 
-		subnet, err := s.netService.FindSubnet(*scope.HCMachine.Spec.Subnet.ID)
+		subnet, err := s.describeSubnet(ctx, *scope.HCMachine.Spec.Subnet.ID)
 		if err != nil {
 			return "", errors.Wrapf(err, "failed to find subnet %s", *scope.HCMachine.Spec.Subnet.ID)
 		}
@@ -100,6 +102,8 @@ func (s *Service) GetCoreSecurityGroups(scope *scope.MachineScope) ([]string, er
 	switch scope.Role() {
 	case "control-plane":
 		sgRoles = append(sgRoles, infrav1.SecurityGroupControlPlane)
+	case "node":
+		// Just the common security groups above
 	default:
 		return nil, errors.Errorf("Unknown node role %q", scope.Role())
 	}
@@ -131,7 +135,7 @@ func generateInstanceName(prefix string) string {
 	return fmt.Sprintf("%s-%s", prefix, string(suffix))
 }
 
-func (s *Service) CreateInstance(scope *scope.MachineScope, userData []byte,
+func (s *Service) CreateInstance(ctx context.Context, scope *scope.MachineScope, userData []byte,
 	userDataFormat string,
 ) (*infrav1.Instance, error) {
 	scope.Logger.Info("Creating ECS instance")
@@ -151,7 +155,7 @@ func (s *Service) CreateInstance(scope *scope.MachineScope, userData []byte,
 		input.ImageID = *scope.HCMachine.Spec.ImageRef
 	}
 
-	subnetID, err := s.findSubnet(scope)
+	subnetID, err := s.findSubnet(ctx, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +178,7 @@ func (s *Service) CreateInstance(scope *scope.MachineScope, userData []byte,
 	}
 	input.SecurityGroupIDs = append(input.SecurityGroupIDs, ids...)
 
-	out, err := s.runInstance(input)
+	out, err := s.runInstance(ctx, input)
 	if err != nil {
 		return nil, err
 	}
@@ -182,7 +186,7 @@ func (s *Service) CreateInstance(scope *scope.MachineScope, userData []byte,
 	return out, nil
 }
 
-func (s *Service) runInstance(i *infrav1.Instance) (*infrav1.Instance, error) {
+func (s *Service) runInstance(ctx context.Context, i *infrav1.Instance) (*infrav1.Instance, error) {
 	createReq := &ecsModel.CreateServersRequest{
 		XClientToken: ptr.To(string(uuid.NewUUID())),
 		Body: &ecsModel.CreateServersRequestBody{
@@ -201,6 +205,14 @@ func (s *Service) runInstance(i *infrav1.Instance) (*infrav1.Instance, error) {
 		},
 	}
 
+	ipv6Enabled, err := s.isIPv6Subnet(ctx, i.SubnetID)
+	if err != nil {
+		return nil, err
+	}
+	if ipv6Enabled {
+		createReq.Body.Server.Nics[0].Ipv6Enable = ptr.To(true)
+	}
+
 	if i.PublicIPOnLaunch != nil {
 		createReq.Body.Server.Publicip = &ecsModel.PrePaidServerPublicip{
 			DeleteOnTermination: ptr.To(true),
@@ -232,16 +244,16 @@ func (s *Service) runInstance(i *infrav1.Instance) (*infrav1.Instance, error) {
 		createReq.Body.Server.RootVolume.Volumetype = ecsModel.GetPrePaidServerRootVolumeVolumetypeEnum().GPSSD
 	}
 
-	response, err := s.ECSClient.CreateServers(createReq)
+	response, err := s.ECSClient.CreateServers(ctx, createReq)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to run instance")
 	}
 
-	if err := s.CheckJob(*response.JobId); err != nil {
+	if err := s.CheckJob(ctx, *response.JobId); err != nil {
 		return nil, errors.Wrap(err, "failed to check job")
 	}
 
-	sdkInstance, err := s.ShowInstance((*response.ServerIds)[0])
+	sdkInstance, err := s.ShowInstance(ctx, (*response.ServerIds)[0])
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to show server")
 	}
@@ -249,8 +261,32 @@ func (s *Service) runInstance(i *infrav1.Instance) (*infrav1.Instance, error) {
 	return s.SDKToInstance(sdkInstance)
 }
 
-func (s *Service) TerminateInstance(id string) error {
-	_, err := s.ECSClient.DeleteServers(&ecsModel.DeleteServersRequest{
+// isIPv6Subnet reports whether IPv6 is enabled on the given subnet, in which case
+// the instance NIC should request an IPv6 address.
+func (s *Service) isIPv6Subnet(ctx context.Context, subnetID string) (bool, error) {
+	if subnet := s.scope.Subnets().FindByID(subnetID); subnet != nil {
+		return subnet.IsIPv6, nil
+	}
+
+	subnet, err := s.describeSubnet(ctx, subnetID)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to find subnet %s", subnetID)
+	}
+	return subnet.Ipv6Enable, nil
+}
+
+// describeSubnet returns the subnet with the given ID.
+func (s *Service) describeSubnet(ctx context.Context, subnetID string) (*vpcModel.Subnet, error) {
+	response, err := s.vpcClient.ShowSubnet(ctx, &vpcModel.ShowSubnetRequest{SubnetId: subnetID})
+	if err != nil {
+		return nil, err
+	}
+	return response.Subnet, nil
+}
+
+// TerminateInstance deletes the instance with its volumes and EIPs, it returns the ID of the deletion job.
+func (s *Service) TerminateInstance(ctx context.Context, id string) (string, error) {
+	response, err := s.ECSClient.DeleteServers(ctx, &ecsModel.DeleteServersRequest{
 		Body: &ecsModel.DeleteServersRequestBody{
 			Servers: []ecsModel.ServerId{
 				{
@@ -262,10 +298,38 @@ func (s *Service) TerminateInstance(id string) error {
 		},
 	})
 	if err != nil {
-		return errors.Wrap(err, "failed to delete server")
+		return "", errors.Wrap(err, "failed to delete server")
+	}
+	if response.JobId == nil {
+		return "", errors.Errorf("no deletion job returned for server %s", id)
 	}
 
-	return nil
+	return *response.JobId, nil
+}
+
+// DeleteJobStatus returns true once the deletion job succeeded, or an error if it failed.
+func (s *Service) DeleteJobStatus(ctx context.Context, jobId string) (bool, error) {
+	resp, err := s.ECSClient.ShowJob(ctx, &ecsModel.ShowJobRequest{
+		JobId: jobId,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to show job %s", jobId)
+	}
+	if resp.Status == nil {
+		// the job status is not known yet, it is checked again on the next reconcile
+		klog.Infof("Job %s has no status yet", jobId)
+		return false, nil
+	}
+
+	switch *resp.Status {
+	case ecsModel.GetShowJobResponseStatusEnum().SUCCESS:
+		return true, nil
+	case ecsModel.GetShowJobResponseStatusEnum().FAIL:
+		return false, errors.Wrapf(ErrDeleteJobFailed, "job %s, errorcode: %s, reason: %s",
+			jobId, ptr.Deref(resp.ErrorCode, ""), ptr.Deref(resp.FailReason, ""))
+	default:
+		return false, nil
+	}
 }
 
 func (s *Service) SDKToInstance(v *ecsModel.ShowServerResponse) (*infrav1.Instance, error) {
@@ -288,6 +352,8 @@ func (s *Service) SDKToInstance(v *ecsModel.ShowServerResponse) (*infrav1.Instan
 		instance.State = infrav1.InstanceStateStopped
 	case "REBOOT", "HARD_REBOOT":
 		instance.State = infrav1.InstanceStateRunning
+	case "DELETING":
+		instance.State = infrav1.InstanceStateShuttingDown
 	case "DELETED", "SOFT_DELETED":
 		instance.State = infrav1.InstanceStateTerminated
 	default:
@@ -297,7 +363,7 @@ func (s *Service) SDKToInstance(v *ecsModel.ShowServerResponse) (*infrav1.Instan
 	// Get private IP from the first available network interface
 	for _, addresses := range v.Server.Addresses {
 		for _, addr := range addresses {
-			if *addr.OSEXTIPStype == ecsModel.GetServerAddressOSEXTIPStypeEnum().FIXED {
+			if *addr.OSEXTIPStype == ecsModel.GetServerAddressOSEXTIPStypeEnum().FIXED && addr.Version == "4" {
 				instance.PrivateIP = &addr.Addr
 				break
 			}
@@ -307,6 +373,19 @@ func (s *Service) SDKToInstance(v *ecsModel.ShowServerResponse) (*infrav1.Instan
 		}
 	}
 
+	// Get IPv6 address from the first available network interface
+	for _, addresses := range v.Server.Addresses {
+		for _, addr := range addresses {
+			if *addr.OSEXTIPStype == ecsModel.GetServerAddressOSEXTIPStypeEnum().FIXED && addr.Version == "6" {
+				instance.IPv6Address = &addr.Addr
+				break
+			}
+		}
+		if instance.IPv6Address != nil {
+			break
+		}
+	}
+
 	// Get public IP from the first available network interface
 	for _, addresses := range v.Server.Addresses {
 		for _, addr := range addresses {
@@ -329,30 +408,32 @@ func (s *Service) SDKToInstance(v *ecsModel.ShowServerResponse) (*infrav1.Instan
 	return instance, nil
 }
 
-func (s *Service) ShowInstance(serverId string) (*ecsModel.ShowServerResponse, error) {
-	return s.ECSClient.ShowServer(&ecsModel.ShowServerRequest{
+func (s *Service) ShowInstance(ctx context.Context, serverId string) (*ecsModel.ShowServerResponse, error) {
+	return s.ECSClient.ShowServer(ctx, &ecsModel.ShowServerRequest{
 		ServerId: serverId,
 	})
 }
 
-func (s *Service) CheckJob(jobId string) error {
+func (s *Service) CheckJob(ctx context.Context, jobId string) error {
 	req := &ecsModel.ShowJobRequest{
 		JobId: jobId,
 	}
 
 	timeout := time.After(time.Minute)
 	for {
-		resp, err := s.ECSClient.ShowJob(req)
+		resp, err := s.ECSClient.ShowJob(ctx, req)
 		if err != nil {
 			return fmt.Errorf("failed to show job: %v", err)
 		}
 
-		switch *resp.Status {
+		// a job without a status yet is polled like a pending job
+		status := ptr.Deref(resp.Status, ecsModel.GetShowJobResponseStatusEnum().INIT)
+		switch status {
 		case ecsModel.GetShowJobResponseStatusEnum().SUCCESS:
 			return nil
 		case ecsModel.GetShowJobResponseStatusEnum().FAIL:
 			return fmt.Errorf("job failed, type: %s, errorcode: %s, reason: %s",
-				*resp.JobType, *resp.ErrorCode, *resp.FailReason)
+				ptr.Deref(resp.JobType, ""), ptr.Deref(resp.ErrorCode, ""), ptr.Deref(resp.FailReason, ""))
 		case ecsModel.GetShowJobResponseStatusEnum().INIT, ecsModel.GetShowJobResponseStatusEnum().RUNNING:
 			select {
 			case <-timeout:
@@ -361,20 +442,20 @@ func (s *Service) CheckJob(jobId string) error {
 				continue
 			}
 		default:
-			return fmt.Errorf("unknown job status: %s", *resp.Status)
+			return fmt.Errorf("unknown job status: %s", status.Value())
 		}
 	}
 }
 
-func (s *Service) InstanceIfExists(id *string) (*infrav1.Instance, error) {
+func (s *Service) InstanceIfExists(ctx context.Context, id *string) (*infrav1.Instance, error) {
 	if id == nil {
 		klog.Info("Instance does not have an instance id")
 		return nil, nil
 	}
 
-	klog.Info("Looking for instance by id", "instance-id", *id)
+	klog.InfoS("Looking for instance by id", "instance-id", *id)
 
-	out, err := s.ShowInstance(*id)
+	out, err := s.ShowInstance(ctx, *id)
 	switch {
 	case ecserrors.IsNotFound(err):
 		return nil, ErrInstanceNotFoundByID
@@ -384,3 +465,19 @@ func (s *Service) InstanceIfExists(id *string) (*infrav1.Instance, error) {
 
 	return s.SDKToInstance(out)
 }
+
+// InstanceAddresses returns the addresses of the instance reported in the HuaweiCloudMachine status.
+// The private IPv4 and IPv6 addresses are internal, the EIP is external.
+func InstanceAddresses(instance *infrav1.Instance) []clusterv1.MachineAddress {
+	var addresses []clusterv1.MachineAddress
+	if instance.PrivateIP != nil && *instance.PrivateIP != "" {
+		addresses = append(addresses, clusterv1.MachineAddress{Type: clusterv1.MachineInternalIP, Address: *instance.PrivateIP})
+	}
+	if instance.IPv6Address != nil && *instance.IPv6Address != "" {
+		addresses = append(addresses, clusterv1.MachineAddress{Type: clusterv1.MachineInternalIP, Address: *instance.IPv6Address})
+	}
+	if instance.PublicIP != nil && *instance.PublicIP != "" {
+		addresses = append(addresses, clusterv1.MachineAddress{Type: clusterv1.MachineExternalIP, Address: *instance.PublicIP})
+	}
+	return addresses
+}
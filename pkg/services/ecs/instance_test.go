@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ecs
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+)
+
+func TestInstanceAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		instance *infrav1.Instance
+		want     []clusterv1.MachineAddress
+	}{
+		{
+			name:     "no address",
+			instance: &infrav1.Instance{},
+			want:     nil,
+		},
+		{
+			name:     "private IPv4 only",
+			instance: &infrav1.Instance{PrivateIP: ptr.To("192.168.1.10")},
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.168.1.10"},
+			},
+		},
+		{
+			name: "dual-stack with EIP",
+			instance: &infrav1.Instance{
+				PrivateIP:   ptr.To("192.168.1.10"),
+				IPv6Address: ptr.To("2407:c080::10"),
+				PublicIP:    ptr.To("1.2.3.4"),
+			},
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.168.1.10"},
+				{Type: clusterv1.MachineInternalIP, Address: "2407:c080::10"},
+				{Type: clusterv1.MachineExternalIP, Address: "1.2.3.4"},
+			},
+		},
+		{
+			name:     "empty addresses are skipped",
+			instance: &infrav1.Instance{PrivateIP: ptr.To("192.168.1.10"), IPv6Address: ptr.To("")},
+			want: []clusterv1.MachineAddress{
+				{Type: clusterv1.MachineInternalIP, Address: "192.168.1.10"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(InstanceAddresses(tt.instance)).To(Equal(tt.want))
+		})
+	}
+}
@@ -17,20 +17,19 @@ limitations under the License.
 package ecs
 
 import (
-	ecsiface "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/ecs/v2"
 	"github.com/pkg/errors"
 
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/clients"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
-	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/services/network"
 )
 
 // Service holds a collection of interfaces.
 // The interfaces are broken down like this to group functions together.
 // One alternative is to have a large list of functions from the ecs client.
 type Service struct {
-	scope      scope.ECSScope
-	ECSClient  *ecsiface.EcsClient
-	netService *network.Service
+	scope     scope.ECSScope
+	ECSClient *clients.ECSClient
+	vpcClient *clients.VPCClient
 }
 
 // NewService returns a new service given the ECS api client.
@@ -40,14 +39,14 @@ func NewService(clusterScope scope.ECSScope) (*Service, error) {
 		return nil, errors.Wrap(err, "failed to create ECS client")
 	}
 
-	netSvc, err := network.NewService(clusterScope.(*scope.ClusterScope))
+	vpcClient, err := clients.NewVPCClient(clusterScope.Region(), clusterScope.Credential())
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create network service")
+		return nil, errors.Wrap(err, "failed to create VPC client")
 	}
 
 	return &Service{
-		scope:      clusterScope,
-		ECSClient:  ecsClient,
-		netService: netSvc,
+		scope:     clusterScope,
+		ECSClient: ecsClient,
+		vpcClient: vpcClient,
 	}, nil
 }
@@ -0,0 +1,204 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+const (
+	// apiServerHealthCheckPath is the path of the API server readiness endpoint used by HTTPS health checks.
+	apiServerHealthCheckPath = "/readyz"
+
+	defaultHealthCheckInterval   = 5
+	defaultHealthCheckTimeout    = 3
+	defaultHealthCheckMaxRetries = 3
+)
+
+// healthCheckWithDefaults returns the health check with the defaults applied.
+func healthCheckWithDefaults(spec *infrav1alpha1.ELBHealthCheckSpec) infrav1alpha1.ELBHealthCheckSpec {
+	healthCheck := infrav1alpha1.ELBHealthCheckSpec{
+		Protocol:        infrav1alpha1.ELBHealthCheckProtocolTCP,
+		IntervalSeconds: defaultHealthCheckInterval,
+		TimeoutSeconds:  defaultHealthCheckTimeout,
+		MaxRetries:      defaultHealthCheckMaxRetries,
+	}
+	if spec == nil {
+		return healthCheck
+	}
+	if spec.Protocol != "" {
+		healthCheck.Protocol = spec.Protocol
+	}
+	if spec.IntervalSeconds > 0 {
+		healthCheck.IntervalSeconds = spec.IntervalSeconds
+	}
+	if spec.TimeoutSeconds > 0 {
+		healthCheck.TimeoutSeconds = spec.TimeoutSeconds
+	}
+	if spec.MaxRetries > 0 {
+		healthCheck.MaxRetries = spec.MaxRetries
+	}
+	return healthCheck
+}
+
+// reconcilePools ensures each pool of the load balancer has an up-to-date health monitor
+// and records the health of the pool members in the cluster status.
+func (s *Service) reconcilePools(ctx context.Context, lb *loadBalancer) error {
+	elb := lb.status()
+	for i := range elb.Pools {
+		pool := &elb.Pools[i]
+		healthMonitorId, err := s.reconcileHealthMonitor(ctx, pool.Id, healthCheckWithDefaults(s.healthCheckForPool(lb, pool.Port)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to reconcile health monitor of pool %s", pool.Id)
+		}
+		pool.HealthMonitorId = healthMonitorId
+
+		members, err := s.describePoolMembers(ctx, pool.Id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to list members of pool %s", pool.Id)
+		}
+		pool.Members = members
+	}
+	lb.setStatus(elb)
+	return nil
+}
+
+// reconcileMembersHealth reports the members of the load balancer pools which are not online.
+// The health is refreshed by each reconcile, including the periodic drift check.
+func (s *Service) reconcileMembersHealth() {
+	pools := slices.Concat(s.scope.ELB().Pools, s.scope.SecondaryELB().Pools)
+	unhealthy := unhealthyMembers(pools)
+	if len(unhealthy) == 0 {
+		conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.LoadBalancerMembersHealthyCondition)
+		return
+	}
+	conditions.MarkFalse(s.scope.InfraCluster(),
+		infrav1alpha1.LoadBalancerMembersHealthyCondition,
+		infrav1alpha1.LoadBalancerMembersUnhealthyReason,
+		clusterv1.ConditionSeverityWarning,
+		"members not online: %s", strings.Join(unhealthy, ", "))
+}
+
+// unhealthyMembers returns the address and the status of the pool members which are not online.
+func unhealthyMembers(pools []infrav1alpha1.PoolRef) []string {
+	unhealthy := make([]string, 0)
+	for _, pool := range pools {
+		for _, member := range pool.Members {
+			if member.OperatingStatus != "ONLINE" {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s:%d (%s)", member.Address, pool.Port, member.OperatingStatus))
+			}
+		}
+	}
+	return unhealthy
+}
+
+func (s *Service) reconcileHealthMonitor(ctx context.Context, poolId string, healthCheck infrav1alpha1.ELBHealthCheckSpec) (string, error) {
+	showPoolResponse, err := s.elbClient.ShowPool(ctx, &elbmodel.ShowPoolRequest{PoolId: poolId})
+	if err != nil {
+		return "", err
+	}
+
+	healthMonitorId := showPoolResponse.Pool.HealthmonitorId
+	if healthMonitorId == "" {
+		return s.createHealthMonitor(ctx, poolId, healthCheck)
+	}
+
+	showHealthMonitorResponse, err := s.elbClient.ShowHealthMonitor(ctx, &elbmodel.ShowHealthMonitorRequest{HealthmonitorId: healthMonitorId})
+	if err != nil {
+		return "", err
+	}
+	if healthMonitorMatches(showHealthMonitorResponse.Healthmonitor, healthCheck) {
+		return healthMonitorId, nil
+	}
+
+	request := &elbmodel.UpdateHealthMonitorRequest{
+		HealthmonitorId: healthMonitorId,
+		Body: &elbmodel.UpdateHealthMonitorRequestBody{
+			Healthmonitor: &elbmodel.UpdateHealthMonitorOption{
+				Type:       ptr.To(string(healthCheck.Protocol)),
+				Delay:      ptr.To(healthCheck.IntervalSeconds),
+				Timeout:    ptr.To(healthCheck.TimeoutSeconds),
+				MaxRetries: ptr.To(healthCheck.MaxRetries),
+			},
+		},
+	}
+	if healthCheck.Protocol == infrav1alpha1.ELBHealthCheckProtocolHTTPS {
+		request.Body.Healthmonitor.UrlPath = ptr.To(apiServerHealthCheckPath)
+		request.Body.Healthmonitor.ExpectedCodes = ptr.To("200")
+	}
+	if _, err := s.elbClient.UpdateHealthMonitor(ctx, request); err != nil {
+		return "", err
+	}
+	klog.Infof("Updated health monitor %s of pool %s", healthMonitorId, poolId)
+	return healthMonitorId, nil
+}
+
+func (s *Service) createHealthMonitor(ctx context.Context, poolId string, healthCheck infrav1alpha1.ELBHealthCheckSpec) (string, error) {
+	option := &elbmodel.CreateHealthMonitorOption{
+		Name:       ptr.To(fmt.Sprintf("caph-hm-%s", poolId[:8])),
+		PoolId:     poolId,
+		Type:       string(healthCheck.Protocol),
+		Delay:      healthCheck.IntervalSeconds,
+		Timeout:    healthCheck.TimeoutSeconds,
+		MaxRetries: healthCheck.MaxRetries,
+	}
+	if healthCheck.Protocol == infrav1alpha1.ELBHealthCheckProtocolHTTPS {
+		option.UrlPath = ptr.To(apiServerHealthCheckPath)
+		option.ExpectedCodes = ptr.To("200")
+	}
+	response, err := s.elbClient.CreateHealthMonitor(ctx, &elbmodel.CreateHealthMonitorRequest{
+		Body: &elbmodel.CreateHealthMonitorRequestBody{Healthmonitor: option},
+	})
+	if err != nil {
+		s.scope.Warningf(err, "FailedCreateHealthMonitor", "Failed to create health monitor for pool %s", poolId)
+		return "", err
+	}
+	klog.Infof("Created health monitor %s for pool %s", response.Healthmonitor.Id, poolId)
+	s.scope.Eventf("SuccessfulCreateHealthMonitor", "Created health monitor %s for pool %s", response.Healthmonitor.Id, poolId)
+	return response.Healthmonitor.Id, nil
+}
+
+// healthMonitorMatches returns true if the health monitor is configured as the health check.
+func healthMonitorMatches(healthMonitor *elbmodel.HealthMonitor, healthCheck infrav1alpha1.ELBHealthCheckSpec) bool {
+	if healthMonitor == nil {
+		return false
+	}
+	if healthMonitor.Type != string(healthCheck.Protocol) ||
+		healthMonitor.Delay != healthCheck.IntervalSeconds ||
+		healthMonitor.Timeout != healthCheck.TimeoutSeconds ||
+		healthMonitor.MaxRetries != healthCheck.MaxRetries {
+		return false
+	}
+	if healthCheck.Protocol == infrav1alpha1.ELBHealthCheckProtocolHTTPS {
+		return healthMonitor.UrlPath == apiServerHealthCheckPath
+	}
+	return true
+}
+
+func (s *Service) describePoolMembers(ctx context.Context, poolId string) ([]infrav1alpha1.PoolMember, error) {
+	response, err := s.elbClient.ListMembers(ctx, &elbmodel.ListMembersRequest{PoolId: poolId})
+	if err != nil {
+		return nil, err
+	}
+	members := make([]infrav1alpha1.PoolMember, 0)
+	if response.Members == nil {
+		return members, nil
+	}
+	for _, member := range *response.Members {
+		members = append(members, infrav1alpha1.PoolMember{
+			Id:              member.Id,
+			Address:         member.Address,
+			OperatingStatus: member.OperatingStatus,
+		})
+	}
+	return members, nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elb
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+)
+
+func TestHealthCheckWithDefaults(t *testing.T) {
+	defaults := infrav1alpha1.ELBHealthCheckSpec{
+		Protocol:        infrav1alpha1.ELBHealthCheckProtocolTCP,
+		IntervalSeconds: defaultHealthCheckInterval,
+		TimeoutSeconds:  defaultHealthCheckTimeout,
+		MaxRetries:      defaultHealthCheckMaxRetries,
+	}
+	tests := []struct {
+		name string
+		spec *infrav1alpha1.ELBHealthCheckSpec
+		want infrav1alpha1.ELBHealthCheckSpec
+	}{
+		{
+			name: "nil health check",
+			want: defaults,
+		},
+		{
+			name: "empty health check",
+			spec: &infrav1alpha1.ELBHealthCheckSpec{},
+			want: defaults,
+		},
+		{
+			name: "protocol only",
+			spec: &infrav1alpha1.ELBHealthCheckSpec{Protocol: infrav1alpha1.ELBHealthCheckProtocolHTTPS},
+			want: infrav1alpha1.ELBHealthCheckSpec{
+				Protocol:        infrav1alpha1.ELBHealthCheckProtocolHTTPS,
+				IntervalSeconds: defaultHealthCheckInterval,
+				TimeoutSeconds:  defaultHealthCheckTimeout,
+				MaxRetries:      defaultHealthCheckMaxRetries,
+			},
+		},
+		{
+			name: "all fields set",
+			spec: &infrav1alpha1.ELBHealthCheckSpec{
+				Protocol:        infrav1alpha1.ELBHealthCheckProtocolHTTPS,
+				IntervalSeconds: 10,
+				TimeoutSeconds:  5,
+				MaxRetries:      2,
+			},
+			want: infrav1alpha1.ELBHealthCheckSpec{
+				Protocol:        infrav1alpha1.ELBHealthCheckProtocolHTTPS,
+				IntervalSeconds: 10,
+				TimeoutSeconds:  5,
+				MaxRetries:      2,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(healthCheckWithDefaults(tt.spec)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestUnhealthyMembers(t *testing.T) {
+	tests := []struct {
+		name  string
+		pools []infrav1alpha1.PoolRef
+		want  []string
+	}{
+		{
+			name: "no pool",
+			want: []string{},
+		},
+		{
+			name: "all members online",
+			pools: []infrav1alpha1.PoolRef{{
+				Port: 6443,
+				Members: []infrav1alpha1.PoolMember{
+					{Address: "192.168.1.10", OperatingStatus: "ONLINE"},
+				},
+			}},
+			want: []string{},
+		},
+		{
+			name: "offline members of several pools",
+			pools: []infrav1alpha1.PoolRef{
+				{
+					Port: 6443,
+					Members: []infrav1alpha1.PoolMember{
+						{Address: "192.168.1.10", OperatingStatus: "ONLINE"},
+						{Address: "192.168.1.11", OperatingStatus: "OFFLINE"},
+					},
+				},
+				{
+					Port: 22623,
+					Members: []infrav1alpha1.PoolMember{
+						{Address: "192.168.1.10", OperatingStatus: "NO_MONITOR"},
+					},
+				},
+			},
+			want: []string{"192.168.1.11:6443 (OFFLINE)", "192.168.1.10:22623 (NO_MONITOR)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(unhealthyMembers(tt.pools)).To(Equal(tt.want))
+		})
+	}
+}
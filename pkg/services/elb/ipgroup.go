@@ -0,0 +1,213 @@
+package elb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
+	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// allowedCIDRs returns the CIDRs allowed to access the listeners of the load balancer,
+// including the VPC CIDR and the NAT gateways IPs. It returns nil if access is not restricted.
+func (s *Service) allowedCIDRs(lb *loadBalancer) []string {
+	if len(lb.spec.AllowedCIDRs) == 0 {
+		return nil
+	}
+	cidrs := slices.Clone(lb.spec.AllowedCIDRs)
+	if vpcCidr := s.scope.VPC().Cidr; vpcCidr != "" {
+		cidrs = append(cidrs, vpcCidr)
+	}
+	for _, subnet := range s.scope.Subnets() {
+		if subnet.IsIPv6 && subnet.IPv6CidrBlock != "" {
+			cidrs = append(cidrs, subnet.IPv6CidrBlock)
+		}
+	}
+	for _, ip := range s.scope.Network().NatGatewaysIPs {
+		cidrs = append(cidrs, fmt.Sprintf("%s/32", ip))
+	}
+	slices.Sort(cidrs)
+	return slices.Compact(cidrs)
+}
+
+// reconcileAccessControl restricts the access to all the listeners of the load balancer to the allowed CIDRs
+// with an IP address group whitelist. When the allowed CIDRs are removed, the IP address group is detached
+// from the listeners and deleted.
+func (s *Service) reconcileAccessControl(ctx context.Context, lb *loadBalancer, status *infrav1alpha1.LoadBalancer) error {
+	cidrs := s.allowedCIDRs(lb)
+	if len(cidrs) == 0 {
+		if status.IPGroupId == "" {
+			return nil
+		}
+		if err := s.removeIPGroup(ctx, status.IPGroupId); err != nil {
+			return errors.Wrapf(err, "failed to remove IP address group of load balancer %s", lb.name)
+		}
+		status.IPGroupId = ""
+		return nil
+	}
+	if len(status.Listeners) == 0 {
+		return nil
+	}
+
+	ipGroupId, err := s.reconcileIPGroup(ctx, fmt.Sprintf("%s-apiserver", lb.name), status.IPGroupId, cidrs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to reconcile IP address group of load balancer %s", lb.name)
+	}
+	status.IPGroupId = ipGroupId
+	for _, listener := range status.Listeners {
+		if err := s.attachListenerIPGroup(ctx, listener.Id, ipGroupId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeIPGroup detaches the IP address group from its listeners and deletes it.
+func (s *Service) removeIPGroup(ctx context.Context, id string) error {
+	ipGroup, err := s.getIPGroup(ctx, "", id)
+	if err != nil {
+		return err
+	}
+	if ipGroup == nil {
+		return nil
+	}
+	for _, listener := range ipGroup.Listeners {
+		if _, err := s.elbClient.DetachListenerIpGroup(ctx, listener.Id); err != nil {
+			return errors.Wrapf(err, "failed to detach IP address group %s from listener %s", id, listener.Id)
+		}
+		klog.Infof("Detached IP address group %s from listener %s", id, listener.Id)
+	}
+	return s.deleteIPGroup(ctx, id)
+}
+
+// reconcileIPGroup finds or creates the IP address group and updates its IP list to the CIDRs.
+func (s *Service) reconcileIPGroup(ctx context.Context, name, id string, cidrs []string) (string, error) {
+	ipGroup, err := s.getIPGroup(ctx, name, id)
+	if err != nil {
+		return "", err
+	}
+
+	if ipGroup == nil {
+		ipList := make([]elbmodel.CreateIpGroupIpOption, 0, len(cidrs))
+		for _, cidr := range cidrs {
+			ipList = append(ipList, elbmodel.CreateIpGroupIpOption{Ip: cidr})
+		}
+		response, err := s.elbClient.CreateIpGroup(ctx, &elbmodel.CreateIpGroupRequest{
+			Body: &elbmodel.CreateIpGroupRequestBody{
+				Ipgroup: &elbmodel.CreateIpGroupOption{
+					Name:   ptr.To(name),
+					IpList: ipList,
+				},
+			},
+		})
+		if err != nil {
+			s.scope.Warningf(err, "FailedCreateIPGroup", "Failed to create IP address group %s", name)
+			return "", err
+		}
+		klog.Infof("Created IP address group %s", response.Ipgroup.Id)
+		s.scope.Eventf("SuccessfulCreateIPGroup", "Created IP address group %s (%s)", name, response.Ipgroup.Id)
+		return response.Ipgroup.Id, nil
+	}
+
+	existing := make([]string, 0, len(ipGroup.IpList))
+	for _, ip := range ipGroup.IpList {
+		existing = append(existing, ip.Ip)
+	}
+	slices.Sort(existing)
+	if slices.Equal(existing, cidrs) {
+		return ipGroup.Id, nil
+	}
+
+	ipList := make([]elbmodel.UpadateIpGroupIpOption, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		ipList = append(ipList, elbmodel.UpadateIpGroupIpOption{Ip: cidr})
+	}
+	_, err = s.elbClient.UpdateIpGroup(ctx, &elbmodel.UpdateIpGroupRequest{
+		IpgroupId: ipGroup.Id,
+		Body: &elbmodel.UpdateIpGroupRequestBody{
+			Ipgroup: &elbmodel.UpdateIpGroupOption{IpList: &ipList},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	klog.Infof("Updated IP address group %s: %s", ipGroup.Id, strings.Join(cidrs, ","))
+	return ipGroup.Id, nil
+}
+
+// getIPGroup returns the IP address group with the given ID, or with the given name if the ID is unknown.
+// It returns nil if the IP address group does not exist.
+func (s *Service) getIPGroup(ctx context.Context, name, id string) (*elbmodel.IpGroup, error) {
+	if id != "" {
+		response, err := s.elbClient.ShowIpGroup(ctx, &elbmodel.ShowIpGroupRequest{IpgroupId: id})
+		if err != nil {
+			if ecserrors.StatusCode(err) == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return response.Ipgroup, nil
+	}
+
+	response, err := s.elbClient.ListIpGroups(ctx, &elbmodel.ListIpGroupsRequest{Name: &[]string{name}})
+	if err != nil {
+		return nil, err
+	}
+	if response.Ipgroups == nil || len(*response.Ipgroups) == 0 {
+		return nil, nil
+	}
+	return &(*response.Ipgroups)[0], nil
+}
+
+// attachListenerIPGroup sets the IP address group as the enabled whitelist of the listener.
+func (s *Service) attachListenerIPGroup(ctx context.Context, listenerId, ipGroupId string) error {
+	response, err := s.elbClient.ShowListener(ctx, &elbmodel.ShowListenerRequest{ListenerId: listenerId})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get listener %s", listenerId)
+	}
+	if current := response.Listener.Ipgroup; current != nil &&
+		current.IpgroupId == ipGroupId && current.EnableIpgroup && current.Type == "white" {
+		return nil
+	}
+
+	_, err = s.elbClient.UpdateListener(ctx, &elbmodel.UpdateListenerRequest{
+		ListenerId: listenerId,
+		Body: &elbmodel.UpdateListenerRequestBody{
+			Listener: &elbmodel.UpdateListenerOption{
+				Ipgroup: &elbmodel.UpdateListenerIpGroupOption{
+					IpgroupId:     ptr.To(ipGroupId),
+					EnableIpgroup: ptr.To(true),
+					Type:          ptr.To(elbmodel.GetUpdateListenerIpGroupOptionTypeEnum().WHITE),
+				},
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to update access control of listener %s", listenerId)
+	}
+	klog.Infof("Restricted access to listener %s with IP address group %s", listenerId, ipGroupId)
+	return nil
+}
+
+// deleteIPGroup deletes the IP address group, an IP address group already deleted is ignored.
+func (s *Service) deleteIPGroup(ctx context.Context, id string) error {
+	_, err := s.elbClient.DeleteIpGroup(ctx, &elbmodel.DeleteIpGroupRequest{IpgroupId: id})
+	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			klog.Infof("IP address group %s already deleted", id)
+			return nil
+		}
+		s.scope.Warningf(err, "FailedDeleteIPGroup", "Failed to delete IP address group %s", id)
+		return err
+	}
+	klog.Infof("Deleted IP address group %s", id)
+	s.scope.Eventf("SuccessfulDeleteIPGroup", "Deleted IP address group %s", id)
+	return nil
+}
@@ -0,0 +1,143 @@
+package elb
+
+import (
+	"context"
+	"strings"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// listenerNamePrefix is the name prefix of the listeners owned by the cluster.
+const listenerNamePrefix = "caph-tcp-"
+
+// listenerSpec is a TCP listener of the control plane load balancer forwarding to a pool.
+type listenerSpec struct {
+	port        int32
+	backendPort int32
+	healthCheck *infrav1alpha1.ELBHealthCheckSpec
+}
+
+// listenerSpecs returns the desired listeners of the load balancer, the API server listener first.
+func (s *Service) listenerSpecs(lb *loadBalancer) []listenerSpec {
+	listeners := []listenerSpec{
+		{
+			port:        lb.port(),
+			backendPort: lb.backendPort(),
+			healthCheck: lb.spec.HealthCheck,
+		},
+	}
+	for _, listener := range lb.spec.AdditionalListeners {
+		listeners = append(listeners, listenerSpec{
+			port:        listener.Port,
+			backendPort: listener.Port,
+			healthCheck: listener.HealthCheck,
+		})
+	}
+	return listeners
+}
+
+// reconcileListeners makes sure the load balancer has a listener with a pool for each desired listener,
+// the listeners owned by the cluster which are no longer desired are deleted with their pool.
+func (s *Service) reconcileListeners(ctx context.Context, lb *loadBalancer, lbId string) ([]infrav1alpha1.ListenerRef, []infrav1alpha1.PoolRef, error) {
+	existing, err := s.describeListeners(ctx, lbId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specs := s.listenerSpecs(lb)
+	listeners := make([]infrav1alpha1.ListenerRef, 0, len(specs))
+	pools := make([]infrav1alpha1.PoolRef, 0, len(specs))
+	desiredPorts := map[int32]bool{}
+	for _, spec := range specs {
+		if desiredPorts[spec.port] {
+			return nil, nil, errors.Errorf("duplicate load balancer listener port %d", spec.port)
+		}
+		desiredPorts[spec.port] = true
+
+		listener := infrav1alpha1.ListenerRef{Port: spec.port}
+		for _, existingListener := range existing {
+			if existingListener.ProtocolPort != spec.port {
+				continue
+			}
+			// the load balancer can be provided by the user with listeners of its own
+			if !strings.HasPrefix(existingListener.Name, listenerNamePrefix) {
+				return nil, nil, errors.Errorf("port %d is used by listener %s which is not owned by the cluster", spec.port, existingListener.Id)
+			}
+			listener.Id = existingListener.Id
+			listener.PoolId = existingListener.DefaultPoolId
+			break
+		}
+		if listener.Id == "" {
+			if listener.Id, err = s.createListener(ctx, lbId, spec.port); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to create listener on port %d", spec.port)
+			}
+		}
+		if listener.PoolId == "" {
+			if listener.PoolId, err = s.createPool(ctx, listener.Id); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to create pool for listener %s", listener.Id)
+			}
+		}
+		listeners = append(listeners, listener)
+		pools = append(pools, infrav1alpha1.PoolRef{Id: listener.PoolId, Port: spec.backendPort})
+	}
+
+	for _, existingListener := range existing {
+		if desiredPorts[existingListener.ProtocolPort] || !strings.HasPrefix(existingListener.Name, listenerNamePrefix) {
+			continue
+		}
+		if existingListener.DefaultPoolId != "" {
+			if err := s.deletePoolAndHealthMonitor(ctx, existingListener.DefaultPoolId); err != nil {
+				return nil, nil, err
+			}
+		}
+		if err := s.deleteListener(ctx, existingListener.Id); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to delete listener %s", existingListener.Id)
+		}
+		klog.Infof("Deleted listener %s on port %d", existingListener.Id, existingListener.ProtocolPort)
+	}
+	return listeners, pools, nil
+}
+
+// healthCheckForPool returns the health check configured for the pool with the given member port.
+func (s *Service) healthCheckForPool(lb *loadBalancer, port int32) *infrav1alpha1.ELBHealthCheckSpec {
+	for _, spec := range s.listenerSpecs(lb) {
+		if spec.backendPort == port {
+			return spec.healthCheck
+		}
+	}
+	return nil
+}
+
+func (s *Service) describeListeners(ctx context.Context, lbId string) ([]elbmodel.Listener, error) {
+	request := &elbmodel.ListListenersRequest{
+		LoadbalancerId: &[]string{lbId},
+	}
+	response, err := s.elbClient.ListListeners(ctx, request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list listeners of load balancer %s", lbId)
+	}
+	if response.Listeners == nil {
+		return nil, nil
+	}
+	return *response.Listeners, nil
+}
+
+// deletePoolAndHealthMonitor deletes the pool, a pool can only be deleted once its health monitor is deleted.
+func (s *Service) deletePoolAndHealthMonitor(ctx context.Context, poolId string) error {
+	showPoolResponse, err := s.elbClient.ShowPool(ctx, &elbmodel.ShowPoolRequest{PoolId: poolId})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get pool %s", poolId)
+	}
+	if healthMonitorId := showPoolResponse.Pool.HealthmonitorId; healthMonitorId != "" {
+		if err := s.deleteHealthMonitor(ctx, healthMonitorId); err != nil {
+			return errors.Wrapf(err, "failed to delete health monitor %s", healthMonitorId)
+		}
+	}
+	if err := s.deletePool(ctx, poolId); err != nil {
+		return errors.Wrapf(err, "failed to delete pool %s", poolId)
+	}
+	return nil
+}
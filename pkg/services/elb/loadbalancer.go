@@ -1,14 +1,19 @@
 package elb
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"slices"
 	"strings"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	eipmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/eip/v2/model"
 	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
@@ -18,6 +23,86 @@ type HuaweiElbPool struct {
 	Port int32  `json:"port,omitempty"`
 }
 
+// errEndpointAddressNotFound is returned when the EIP of the control plane endpoint no longer exists,
+// a new load balancer would change the control plane endpoint.
+var errEndpointAddressNotFound = errors.New("address of the control plane endpoint not found")
+
+// loadBalancer is a control plane load balancer of the cluster, either the primary load balancer
+// or the secondary internal load balancer used for the API traffic within the VPC.
+type loadBalancer struct {
+	name      string
+	secondary bool
+	spec      *infrav1alpha1.HuaweiCloudLoadBalancerSpec
+	status    func() infrav1alpha1.LoadBalancer
+	setStatus func(infrav1alpha1.LoadBalancer)
+}
+
+// scheme returns the scheme of the load balancer, the secondary load balancer is always internal.
+func (lb *loadBalancer) scheme() infrav1alpha1.ELBScheme {
+	if lb.secondary {
+		return infrav1alpha1.ELBSchemeInternal
+	}
+	if lb.spec.Scheme == "" {
+		return infrav1alpha1.ELBSchemeInternetFacing
+	}
+	return lb.spec.Scheme
+}
+
+// port returns the port of the API server listener.
+func (lb *loadBalancer) port() int32 {
+	if lb.spec.Port == 0 {
+		return infrav1alpha1.DefaultAPIServerPort
+	}
+	return lb.spec.Port
+}
+
+// backendPort returns the port the API servers listen on.
+func (lb *loadBalancer) backendPort() int32 {
+	if lb.spec.BackendPort == 0 {
+		return infrav1alpha1.DefaultAPIServerPort
+	}
+	return lb.spec.BackendPort
+}
+
+// loadBalancers returns the control plane load balancers of the cluster.
+func (s *Service) loadBalancers() []*loadBalancer {
+	loadBalancers := []*loadBalancer{
+		{
+			name:      fmt.Sprintf("%s-elb", s.scope.ClusterName()),
+			spec:      s.scope.ControlPlaneLoadBalancer(),
+			status:    s.scope.ELB,
+			setStatus: s.scope.SetELB,
+		},
+	}
+	if spec := s.scope.SecondaryControlPlaneLoadBalancer(); spec != nil {
+		loadBalancers = append(loadBalancers, &loadBalancer{
+			name:      fmt.Sprintf("%s-internal-elb", s.scope.ClusterName()),
+			secondary: true,
+			spec:      spec,
+			status:    s.scope.SecondaryELB,
+			setStatus: s.scope.SetSecondaryELB,
+		})
+	}
+	return loadBalancers
+}
+
+// validateLoadBalancers checks the configuration of the secondary load balancer,
+// it has to be internal and the primary load balancer internet-facing.
+func (s *Service) validateLoadBalancers(loadBalancers []*loadBalancer) error {
+	for _, lb := range loadBalancers {
+		if !lb.secondary {
+			continue
+		}
+		if lb.spec.Scheme == infrav1alpha1.ELBSchemeInternetFacing {
+			return errors.New("the secondary control plane load balancer must be internal")
+		}
+		if loadBalancers[0].scheme() != infrav1alpha1.ELBSchemeInternetFacing {
+			return errors.New("the control plane load balancer must be internet-facing when a secondary load balancer is configured")
+		}
+	}
+	return nil
+}
+
 func getLoadBalancerChargeMode(chargeMode string) elbmodel.CreateLoadBalancerBandwidthOptionChargeMode {
 	var chargeModeEnum elbmodel.CreateLoadBalancerBandwidthOptionChargeMode
 	switch chargeMode {
@@ -44,9 +129,9 @@ func getLoadBalancerShareType(shareType string) elbmodel.CreateLoadBalancerBandw
 	return shareTypeEnum
 }
 
-func (s *Service) createListener(lbId string, port int32) (string, error) {
+func (s *Service) createListener(ctx context.Context, lbId string, port int32) (string, error) {
 	request := &elbmodel.CreateListenerRequest{}
-	name := fmt.Sprintf("caph-tcp-%d", port)
+	name := fmt.Sprintf("%s%d", listenerNamePrefix, port)
 	listenerbody := &elbmodel.CreateListenerOption{
 		LoadbalancerId: lbId,
 		Name:           &name,
@@ -56,19 +141,17 @@ func (s *Service) createListener(lbId string, port int32) (string, error) {
 	request.Body = &elbmodel.CreateListenerRequestBody{
 		Listener: listenerbody,
 	}
-	response, err := s.elbClient.CreateListener(request)
+	response, err := s.elbClient.CreateListener(ctx, request)
 	if err != nil {
-		// listener is already exists
-		if strings.Contains(err.Error(), "ELB.8907") {
-			return "", nil
-		}
+		s.scope.Warningf(err, "FailedCreateListener", "Failed to create listener %s of load balancer %s", name, lbId)
 		return "", err
 	}
-	fmt.Println("create listener success")
+	klog.Infof("Created listener %s of load balancer %s", response.Listener.Id, lbId)
+	s.scope.Eventf("SuccessfulCreateListener", "Created listener %s of load balancer %s", response.Listener.Id, lbId)
 	return response.Listener.Id, nil
 }
 
-func (s *Service) createPool(listenerId string) (string, error) {
+func (s *Service) createPool(ctx context.Context, listenerId string) (string, error) {
 	request := &elbmodel.CreatePoolRequest{}
 	name := fmt.Sprintf("caph-svc-gp-%s", listenerId[:8])
 	typePool := "instance"
@@ -80,114 +163,298 @@ func (s *Service) createPool(listenerId string) (string, error) {
 		VpcId:       &s.scope.VPC().Id,
 		Type:        &typePool,
 	}
+	// A dual-stack pool lets the listener forward both IPv4 and IPv6 traffic
+	// received on the load balancer VIPs to the backend servers.
+	if s.scope.VPC().IPv6Enabled {
+		poolbody.IpVersion = ptr.To("dualstack")
+	}
 	request.Body = &elbmodel.CreatePoolRequestBody{
 		Pool: poolbody,
 	}
-	response, err := s.elbClient.CreatePool(request)
+	response, err := s.elbClient.CreatePool(ctx, request)
 	if err != nil {
+		s.scope.Warningf(err, "FailedCreatePool", "Failed to create pool %s of listener %s", name, listenerId)
 		return "", err
 	}
-	fmt.Println("create pool success")
+	klog.Infof("Created pool %s of listener %s", response.Pool.Id, listenerId)
+	s.scope.Eventf("SuccessfulCreatePool", "Created pool %s of listener %s", response.Pool.Id, listenerId)
 	return response.Pool.Id, nil
 }
 
-func (s *Service) deleteListener(listenerId string) error {
+// deleteListener deletes the listener, a listener already deleted is ignored.
+func (s *Service) deleteListener(ctx context.Context, listenerId string) error {
 	req := &elbmodel.DeleteListenerRequest{ListenerId: listenerId}
-	_, err := s.elbClient.DeleteListener(req)
+	_, err := s.elbClient.DeleteListener(ctx, req)
 	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			klog.Infof("Listener %s already deleted", listenerId)
+			return nil
+		}
+		s.scope.Warningf(err, "FailedDeleteListener", "Failed to delete listener %s", listenerId)
 		return err
 	}
+	s.scope.Eventf("SuccessfulDeleteListener", "Deleted listener %s", listenerId)
 	return nil
 }
 
-func (s *Service) deletePool(poolId string) error {
+// deleteHealthMonitor deletes the health monitor, a health monitor already deleted is ignored.
+func (s *Service) deleteHealthMonitor(ctx context.Context, healthMonitorId string) error {
+	req := &elbmodel.DeleteHealthMonitorRequest{HealthmonitorId: healthMonitorId}
+	_, err := s.elbClient.DeleteHealthMonitor(ctx, req)
+	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			klog.Infof("Health monitor %s already deleted", healthMonitorId)
+			return nil
+		}
+		s.scope.Warningf(err, "FailedDeleteHealthMonitor", "Failed to delete health monitor %s", healthMonitorId)
+		return err
+	}
+	s.scope.Eventf("SuccessfulDeleteHealthMonitor", "Deleted health monitor %s", healthMonitorId)
+	return nil
+}
+
+// deletePool deletes the pool, a pool already deleted is ignored.
+func (s *Service) deletePool(ctx context.Context, poolId string) error {
 	req := &elbmodel.DeletePoolRequest{PoolId: poolId}
-	_, err := s.elbClient.DeletePool(req)
+	_, err := s.elbClient.DeletePool(ctx, req)
 	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			klog.Infof("Pool %s already deleted", poolId)
+			return nil
+		}
+		s.scope.Warningf(err, "FailedDeletePool", "Failed to delete pool %s", poolId)
 		return err
 	}
+	s.scope.Eventf("SuccessfulDeletePool", "Deleted pool %s", poolId)
 	return nil
 }
 
 // ReconcileLoadbalancers reconciles the load balancers for the given cluster.
-func (s *Service) ReconcileLoadbalancers() error {
+func (s *Service) ReconcileLoadbalancers(ctx context.Context) error {
 	klog.Info("Reconciling load balancers")
-	if s.scope.ELB().Id != "" {
-		klog.Info("Load balancer already exists")
-		return nil
+	if s.scope.ControlPlaneLoadBalancer().Disabled {
+		return s.reconcileExternalControlPlaneEndpoint()
+	}
+
+	loadBalancers := s.loadBalancers()
+	if err := s.validateLoadBalancers(loadBalancers); err != nil {
+		return err
+	}
+	for _, lb := range loadBalancers {
+		// the load balancer is looked up on each reconcile to detect a deletion out of band
+		if err := s.reconcileLoadBalancer(ctx, lb); err != nil {
+			return err
+		}
+
+		status := lb.status()
+		listeners, pools, err := s.reconcileListeners(ctx, lb, status.Id)
+		if err != nil {
+			return errors.Wrapf(err, "failed to reconcile listeners of load balancer %s", status.Name)
+		}
+		status.Listeners = listeners
+		status.Pools = pools
+		err = s.reconcileAccessControl(ctx, lb, &status)
+		lb.setStatus(status)
+		if err != nil {
+			return err
+		}
+
+		if err := s.reconcilePools(ctx, lb); err != nil {
+			return err
+		}
+	}
+	s.reconcileMembersHealth()
+
+	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.LoadBalancerReadyCondition)
+	if err := s.scope.PatchObject(); err != nil {
+		return fmt.Errorf("failed to patch HCCluster: %v", err)
+	}
+	return nil
+}
+
+// reconcileExternalControlPlaneEndpoint validates the control plane endpoint provided by the user
+// when the load balancer is disabled.
+func (s *Service) reconcileExternalControlPlaneEndpoint() error {
+	klog.Info("Load balancer is disabled, using the provided control plane endpoint")
+	if !s.scope.HCCluster.Spec.ControlPlaneEndpoint.IsValid() {
+		conditions.MarkFalse(
+			s.scope.InfraCluster(),
+			infrav1alpha1.LoadBalancerReadyCondition,
+			infrav1alpha1.ControlPlaneEndpointNotSetReason,
+			clusterv1.ConditionSeverityError,
+			"the load balancer is disabled and no control plane endpoint is set")
+		if err := s.scope.PatchObject(); err != nil {
+			return fmt.Errorf("failed to patch HCCluster: %v", err)
+		}
+		return errors.New("load balancer is disabled but the control plane endpoint is not set")
+	}
+
+	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.LoadBalancerReadyCondition)
+	if err := s.scope.PatchObject(); err != nil {
+		return fmt.Errorf("failed to patch HCCluster: %v", err)
 	}
+	return nil
+}
 
-	lbName := fmt.Sprintf("%s-elb", s.scope.ClusterName())
-	lb, err := s.getLoadBalancerByName(lbName)
+// reconcileLoadBalancer finds or creates the load balancer and sets the control plane endpoint
+// to its address, the internal control plane endpoint for the secondary load balancer.
+// A load balancer deleted out of band is re-created with the address of the endpoint.
+func (s *Service) reconcileLoadBalancer(ctx context.Context, loadBalancer *loadBalancer) error {
+	lbName := loadBalancer.name
+	lb, err := s.getLoadBalancer(ctx, loadBalancer)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get load balancer %s", lbName)
 	}
 
-	if lb == nil {
-		klog.Info("Creating new load balancer", "name", lbName)
-		if err := s.createLoadBalancer(lbName); err != nil {
+	if lb == nil && loadBalancer.spec.ID != "" {
+		conditions.MarkFalse(
+			s.scope.InfraCluster(),
+			infrav1alpha1.LoadBalancerReadyCondition,
+			infrav1alpha1.LoadBalancerNotFoundReason,
+			clusterv1.ConditionSeverityError,
+			"load balancer %s not found", loadBalancer.spec.ID)
+		return errors.Errorf("load balancer %s not found", loadBalancer.spec.ID)
+	} else if lb == nil {
+		if recorded := loadBalancer.status().Id; recorded != "" {
+			klog.Warningf("Load balancer %s no longer exists, re-creating it", recorded)
+		}
+		klog.InfoS("Creating new load balancer", "name", lbName)
+		if err := s.createLoadBalancer(ctx, loadBalancer); err != nil {
+			if errors.Is(err, errEndpointAddressNotFound) {
+				conditions.MarkFalse(
+					s.scope.InfraCluster(),
+					infrav1alpha1.LoadBalancerReadyCondition,
+					infrav1alpha1.LoadBalancerNotFoundReason,
+					clusterv1.ConditionSeverityError,
+					"load balancer %s no longer exists and cannot be re-created: %v", loadBalancer.status().Id, err)
+			}
 			return errors.Wrapf(err, "failed to create load balancer %s", lbName)
 		}
 		// Re-fetch the load balancer after creation
-		lb, err = s.getLoadBalancerByName(lbName)
+		lb, err = s.getLoadBalancerByName(ctx, lbName)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get load balancer %s after creation", lbName)
 		}
+		if lb == nil {
+			return errors.Errorf("load balancer %s not found after creation", lbName)
+		}
 	} else {
-		klog.Info("Load balancer already exists", "name", lbName)
+		klog.InfoS("Load balancer already exists", "name", lbName)
 	}
 
-	if lb != nil {
-
-		listenerId, err := s.createListener(lb.Id, 6443)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create listener for load balancer %s", lbName)
-		}
-
-		poolId, err := s.createPool(listenerId)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create pool for load balancer %s", lbName)
-		}
+	host, err := s.controlPlaneEndpointHost(loadBalancer, lb)
+	if err != nil {
+		return err
+	}
 
-		s.scope.SetELB(infrav1alpha1.LoadBalancer{
-			Id:   lb.Id,
-			Name: lb.Name,
-			Pools: []infrav1alpha1.PoolRef{
-				{Id: poolId},
-			},
-			Listeners: []infrav1alpha1.ListenerRef{
-				{Id: listenerId},
-			},
-		})
+	status := loadBalancer.status()
+	if status.Id != lb.Id {
+		// the listeners and pools of a load balancer deleted out of band are gone with it
+		status = infrav1alpha1.LoadBalancer{}
+	}
+	status.Id = lb.Id
+	status.Name = lb.Name
+	status.VipAddress = lb.VipAddress
+	status.IPv6VipAddress = lb.Ipv6VipAddress
+	loadBalancer.setStatus(status)
+
+	endpoint := clusterv1.APIEndpoint{
+		Host: host,
+		Port: loadBalancer.port(),
+	}
+	if loadBalancer.secondary {
+		s.scope.SetInternalControlPlaneEndpoint(endpoint)
+	} else {
+		s.scope.HCCluster.Spec.ControlPlaneEndpoint = endpoint
+	}
+	return nil
+}
 
-		s.scope.HCCluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{
-			Host: lb.Publicips[0].PublicipAddress,
-			Port: 6443,
+// controlPlaneEndpointHost returns the address the API servers are reachable at through the load balancer,
+// the EIP of an internet-facing load balancer or the VIP of an internal one.
+func (s *Service) controlPlaneEndpointHost(loadBalancer *loadBalancer, lb *elbmodel.LoadBalancer) (string, error) {
+	if loadBalancer.scheme() == infrav1alpha1.ELBSchemeInternal {
+		if lb.VipAddress == "" {
+			return "", errors.Errorf("load balancer %s has no VIP address", lb.Name)
 		}
+		return lb.VipAddress, nil
 	}
+	if len(lb.Publicips) == 0 {
+		return "", errors.Errorf("internet-facing load balancer %s has no public ip", lb.Name)
+	}
+	return lb.Publicips[0].PublicipAddress, nil
+}
 
-	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.LoadBalancerReadyCondition)
-	if err := s.scope.PatchObject(); err != nil {
-		return fmt.Errorf("failed to patch HCCluster: %v", err)
+// vipSubnet returns the subnet the VIP of the load balancer is allocated in.
+func (s *Service) vipSubnet(lb *loadBalancer) (*infrav1alpha1.SubnetSpec, error) {
+	if len(s.scope.Subnets()) == 0 {
+		return nil, errors.New("no subnets available for the load balancer")
 	}
-	return nil
+	subnetID := lb.spec.SubnetID
+	if subnetID == "" {
+		return &s.scope.Subnets()[0], nil
+	}
+	subnet := s.scope.Subnets().FindByID(subnetID)
+	if subnet == nil {
+		return nil, errors.Errorf("load balancer subnet %s is not a cluster subnet", subnetID)
+	}
+	return subnet, nil
 }
 
 // DeleteLoadbalancers deletes the load balancers for the given cluster.
-func (s *Service) DeleteLoadbalancers() error {
+func (s *Service) DeleteLoadbalancers(ctx context.Context) error {
 	klog.Info("Deleting load balancers")
+	if s.scope.ControlPlaneLoadBalancer().Disabled {
+		klog.Info("Load balancer is disabled, skipping deletion")
+		return nil
+	}
+
+	loadBalancers := s.loadBalancers()
+	// delete the secondary load balancer first
+	for i := len(loadBalancers) - 1; i >= 0; i-- {
+		if err := s.deleteLoadBalancerResources(ctx, loadBalancers[i]); err != nil {
+			return err
+		}
+	}
+
+	conditions.MarkFalse(
+		s.scope.InfraCluster(),
+		infrav1alpha1.LoadBalancerReadyCondition,
+		clusterv1.DeletedReason,
+		clusterv1.ConditionSeverityInfo,
+		"")
+	return nil
+}
 
-	lbName := fmt.Sprintf("%s-elb", s.scope.ClusterName())
-	lb, err := s.getLoadBalancerByName(lbName)
+// deleteLoadBalancerResources deletes the load balancer with its listeners, pools and EIPs.
+// A load balancer provided by the user is kept, only the listeners and pools of the cluster are deleted.
+func (s *Service) deleteLoadBalancerResources(ctx context.Context, loadBalancer *loadBalancer) error {
+	lbName := loadBalancer.name
+	lb, err := s.getLoadBalancer(ctx, loadBalancer)
 	if err != nil {
 		return errors.Wrapf(err, "failed to get load balancer %s", lbName)
 	}
 
+	// the deleted resources are removed from the status, so that they are not deleted again
+	// when the deletion is resumed after a failure
+	status := loadBalancer.status()
 	if lb != nil {
-
-		for i := range s.scope.ELB().Pools {
-			pool := s.scope.ELB().Pools[i]
-			if err := s.deletePool(pool.Id); err != nil {
+		for len(status.Pools) > 0 {
+			pool := &status.Pools[0]
+			if pool.HealthMonitorId != "" {
+				if err := s.deleteHealthMonitor(ctx, pool.HealthMonitorId); err != nil {
+					conditions.MarkFalse(
+						s.scope.InfraCluster(),
+						infrav1alpha1.LoadBalancerReadyCondition,
+						clusterv1.DeletingReason,
+						clusterv1.ConditionSeverityWarning,
+						"failed to delete health monitor")
+					return errors.Wrapf(err, "failed to delete health monitor %s", pool.HealthMonitorId)
+				}
+				pool.HealthMonitorId = ""
+				loadBalancer.setStatus(status)
+			}
+			if err := s.deletePool(ctx, pool.Id); err != nil {
 				conditions.MarkFalse(
 					s.scope.InfraCluster(),
 					infrav1alpha1.LoadBalancerReadyCondition,
@@ -196,11 +463,13 @@ func (s *Service) DeleteLoadbalancers() error {
 					"failed to delete pool")
 				return errors.Wrapf(err, "failed to delete pool %s", pool.Id)
 			}
+			status.Pools = status.Pools[1:]
+			loadBalancer.setStatus(status)
 		}
 
-		for i := range s.scope.ELB().Listeners {
-			listener := s.scope.ELB().Listeners[i]
-			if err := s.deleteListener(listener.Id); err != nil {
+		for len(status.Listeners) > 0 {
+			listener := status.Listeners[0]
+			if err := s.deleteListener(ctx, listener.Id); err != nil {
 				conditions.MarkFalse(
 					s.scope.InfraCluster(),
 					infrav1alpha1.LoadBalancerReadyCondition,
@@ -209,10 +478,45 @@ func (s *Service) DeleteLoadbalancers() error {
 					"failed to delete listener")
 				return errors.Wrapf(err, "failed to delete listener %s", listener.Id)
 			}
+			status.Listeners = status.Listeners[1:]
+			loadBalancer.setStatus(status)
+		}
+
+		// the IP address group can only be deleted once it is no longer used by a listener
+		if status.IPGroupId != "" {
+			if err := s.deleteIPGroup(ctx, status.IPGroupId); err != nil {
+				conditions.MarkFalse(
+					s.scope.InfraCluster(),
+					infrav1alpha1.LoadBalancerReadyCondition,
+					clusterv1.DeletingReason,
+					clusterv1.ConditionSeverityWarning,
+					"failed to delete IP address group")
+				return errors.Wrapf(err, "failed to delete IP address group %s", status.IPGroupId)
+			}
+			status.IPGroupId = ""
+			loadBalancer.setStatus(status)
+		}
+
+		if loadBalancer.spec.ID != "" {
+			klog.Infof("Load balancer %s is not owned by the cluster, skipping deletion", lb.Id)
+			return nil
+		}
+
+		// the owned EIPs are recorded before the load balancer is deleted, so that they are still released
+		// when the deletion is resumed after the load balancer is gone
+		for _, publicIp := range lb.Publicips {
+			if eipSpec := loadBalancer.spec.EIP; eipSpec != nil && eipSpec.ID == publicIp.PublicipId {
+				klog.Infof("Public ip %s is not owned by the cluster, skipping release", publicIp.PublicipId)
+				continue
+			}
+			if !slices.Contains(status.EIPIds, publicIp.PublicipId) {
+				status.EIPIds = append(status.EIPIds, publicIp.PublicipId)
+			}
 		}
+		loadBalancer.setStatus(status)
 
-		klog.Info("Deleting load balancer", "name", lbName)
-		if err := s.deleteLoadBalancer(lb.Id); err != nil {
+		klog.InfoS("Deleting load balancer", "name", lbName)
+		if err := s.deleteLoadBalancer(ctx, lb.Id); err != nil {
 			conditions.MarkFalse(
 				s.scope.InfraCluster(),
 				infrav1alpha1.LoadBalancerReadyCondition,
@@ -221,42 +525,64 @@ func (s *Service) DeleteLoadbalancers() error {
 				"failed to delete load balancer")
 			return errors.Wrapf(err, "failed to delete load balancer %s", lbName)
 		}
+	}
 
-		// delete related elastic ip
-		for _, publicIp := range lb.Publicips {
-			delPubIpReq := &eipmodel.DeletePublicipRequest{
-				PublicipId: publicIp.PublicipId,
-			}
-			delPubIpRes, err := s.eipClient.DeletePublicip(delPubIpReq)
-			if err != nil {
-				conditions.MarkFalse(
-					s.scope.InfraCluster(),
-					infrav1alpha1.LoadBalancerReadyCondition,
-					clusterv1.DeletingReason,
-					clusterv1.ConditionSeverityWarning,
-					"failed to delete public ip")
-				return errors.Wrapf(err, "failed to delete public ip %s", publicIp.PublicipId)
-			}
-			klog.Infof("Delete public ip response: %v", delPubIpRes)
+	for len(status.EIPIds) > 0 {
+		if err := s.releaseEIP(ctx, status.EIPIds[0], lbName); err != nil {
+			conditions.MarkFalse(
+				s.scope.InfraCluster(),
+				infrav1alpha1.LoadBalancerReadyCondition,
+				clusterv1.DeletingReason,
+				clusterv1.ConditionSeverityWarning,
+				"failed to delete public ip")
+			return errors.Wrapf(err, "failed to delete public ip %s", status.EIPIds[0])
 		}
+		status.EIPIds = status.EIPIds[1:]
+		loadBalancer.setStatus(status)
 	}
+	return nil
+}
 
-	conditions.MarkFalse(
-		s.scope.InfraCluster(),
-		infrav1alpha1.LoadBalancerReadyCondition,
-		clusterv1.DeletedReason,
-		clusterv1.ConditionSeverityInfo,
-		"")
+// releaseEIP releases an EIP of the deleted load balancer, an EIP already released is ignored.
+func (s *Service) releaseEIP(ctx context.Context, id, lbName string) error {
+	_, err := s.eipClient.DeletePublicip(ctx, &eipmodel.DeletePublicipRequest{PublicipId: id})
+	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			klog.Infof("Public ip %s already released", id)
+			return nil
+		}
+		s.scope.Warningf(err, "FailedReleaseEIP", "Failed to release EIP %s of load balancer %s", id, lbName)
+		return err
+	}
+	klog.Infof("Released public ip %s of load balancer %s", id, lbName)
+	s.scope.Eventf("SuccessfulReleaseEIP", "Released EIP %s of load balancer %s", id, lbName)
 	return nil
 }
 
-func (s *Service) getLoadBalancerByName(name string) (*elbmodel.LoadBalancer, error) {
+// getLoadBalancer returns the load balancer provided by the user, or the load balancer
+// owned by the cluster with the given name. It returns nil if the load balancer does not exist.
+func (s *Service) getLoadBalancer(ctx context.Context, lb *loadBalancer) (*elbmodel.LoadBalancer, error) {
+	id := lb.spec.ID
+	if id == "" {
+		return s.getLoadBalancerByName(ctx, lb.name)
+	}
+	response, err := s.elbClient.ShowLoadBalancer(ctx, &elbmodel.ShowLoadBalancerRequest{LoadbalancerId: id})
+	if err != nil {
+		if ecserrors.StatusCode(err) == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to get load balancer %s", id)
+	}
+	return response.Loadbalancer, nil
+}
+
+func (s *Service) getLoadBalancerByName(ctx context.Context, name string) (*elbmodel.LoadBalancer, error) {
 	names := []string{name}
 	request := &elbmodel.ListLoadBalancersRequest{
 		Name: &names,
 	}
 
-	response, err := s.elbClient.ListLoadBalancers(request)
+	response, err := s.elbClient.ListLoadBalancers(ctx, request)
 	if err != nil {
 		if isNotFoundError(err) {
 			return nil, nil
@@ -275,68 +601,219 @@ func isNotFoundError(err error) bool {
 	return strings.Contains(err.Error(), "APIGW.0101")
 }
 
-func (s *Service) getAvailabilityZones() ([]string, error) {
-	availabilityZones := make([]string, 0)
+// getAvailabilityZones returns the availability zones of the load balancer. The configured zones are
+// validated against the zone sets supported by the ELB service, each set lists the zones usable together.
+// Without configured zones, the first two active zones of the first set are used.
+func (s *Service) getAvailabilityZones(ctx context.Context, lb *loadBalancer) ([]string, error) {
 	request := &elbmodel.ListAvailabilityZonesRequest{}
-	response, err := s.elbClient.ListAvailabilityZones(request)
+	response, err := s.elbClient.ListAvailabilityZones(ctx, request)
 	if err != nil {
 		return nil, err
 	}
-	for _, zones := range *response.AvailabilityZones {
+	if response.AvailabilityZones == nil || len(*response.AvailabilityZones) == 0 {
+		return nil, errors.New("no availability zones available for load balancers")
+	}
+	return selectAvailabilityZones(*response.AvailabilityZones, lb.spec.AvailabilityZones)
+}
+
+// selectAvailabilityZones returns the desired zones if they are active in one of the zone sets,
+// or the first two active zones of the first set when no zone is desired.
+func selectAvailabilityZones(zoneSets [][]elbmodel.AvailabilityZone, desired []string) ([]string, error) {
+	for _, zones := range zoneSets {
+		active := make([]string, 0, len(zones))
 		for _, zone := range zones {
-			availabilityZones = append(availabilityZones, zone.Code)
-			if len(availabilityZones) == 2 {
-				break
+			if zone.State == "ACTIVE" {
+				active = append(active, zone.Code)
+			}
+		}
+		if len(desired) == 0 {
+			if len(active) > 2 {
+				active = active[:2]
 			}
+			return active, nil
+		}
+		if isSubset(desired, active) {
+			return desired, nil
 		}
 	}
-	return availabilityZones, nil
+	return nil, errors.Errorf("availability zones %v are not available or cannot be used together for load balancers", desired)
 }
 
-func (s *Service) createLoadBalancer(name string) error {
-	request := &elbmodel.CreateLoadBalancerRequest{}
-	nameBandwidth := "eip-caph"
+func isSubset(items, set []string) bool {
+	for _, item := range items {
+		if !slices.Contains(set, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateL4Flavor checks the configured L4 flavor exists and can be used.
+func (s *Service) validateL4Flavor(ctx context.Context, flavorID string) error {
+	request := &elbmodel.ListFlavorsRequest{
+		Id: &[]string{flavorID},
+	}
+	response, err := s.elbClient.ListFlavors(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, "failed to list load balancer flavors")
+	}
+	if response.Flavors == nil || len(*response.Flavors) == 0 {
+		return errors.Errorf("load balancer flavor %s not found", flavorID)
+	}
+	flavor := (*response.Flavors)[0]
+	if flavor.Type != "L4" {
+		return errors.Errorf("load balancer flavor %s is a %s flavor, an L4 flavor is required", flavorID, flavor.Type)
+	}
+	if flavor.FlavorSoldOut {
+		return errors.Errorf("load balancer flavor %s is sold out", flavorID)
+	}
+	return nil
+}
+
+// publicIpOption returns the EIP to create with the load balancer, its bandwidth defaults to
+// 100 Mbit/s billed by traffic.
+func (s *Service) publicIpOption(lb *loadBalancer) *elbmodel.CreateLoadBalancerPublicIpOption {
+	eipSpec := lb.spec.EIP
+	if eipSpec == nil {
+		eipSpec = &infrav1alpha1.ELBEIPSpec{}
+	}
+
+	networkType := "5_bgp"
+	if eipSpec.Type != "" {
+		networkType = eipSpec.Type
+	}
+
+	if eipSpec.SharedBandwidthID != "" {
+		shareType := getLoadBalancerShareType("whole")
+		return &elbmodel.CreateLoadBalancerPublicIpOption{
+			NetworkType: networkType,
+			Bandwidth: &elbmodel.CreateLoadBalancerBandwidthOption{
+				Id:        ptr.To(eipSpec.SharedBandwidthID),
+				ShareType: &shareType,
+			},
+		}
+	}
+
+	nameBandwidth := lb.name
 	chargeMode := getLoadBalancerChargeMode("traffic")
 	shareType := getLoadBalancerShareType("per")
 	var bwSize int32 = 100
-	bandwidthPublicip := &elbmodel.CreateLoadBalancerBandwidthOption{
-		Name:       &nameBandwidth,
-		Size:       &bwSize,
-		ChargeMode: &chargeMode,
-		ShareType:  &shareType,
+	if eipSpec.Bandwidth != nil {
+		chargeMode = getLoadBalancerChargeMode(string(eipSpec.Bandwidth.ChargeMode))
+		if eipSpec.Bandwidth.Size > 0 {
+			bwSize = eipSpec.Bandwidth.Size
+		}
 	}
-	publicipLoadbalancer := &elbmodel.CreateLoadBalancerPublicIpOption{
-		NetworkType: "5_bgp",
-		Bandwidth:   bandwidthPublicip,
+	return &elbmodel.CreateLoadBalancerPublicIpOption{
+		NetworkType: networkType,
+		Bandwidth: &elbmodel.CreateLoadBalancerBandwidthOption{
+			Name:       &nameBandwidth,
+			Size:       &bwSize,
+			ChargeMode: &chargeMode,
+			ShareType:  &shareType,
+		},
 	}
-	zones, err := s.getAvailabilityZones()
+}
+
+func (s *Service) createLoadBalancer(ctx context.Context, lb *loadBalancer) error {
+	request := &elbmodel.CreateLoadBalancerRequest{}
+	subnet, err := s.vipSubnet(lb)
+	if err != nil {
+		return err
+	}
+	zones, err := s.getAvailabilityZones(ctx, lb)
 	if err != nil {
 		return err
 	}
 	loadbalancerbody := &elbmodel.CreateLoadBalancerOption{
-		Name:                 &name,
-		VipSubnetCidrId:      &s.scope.Subnets()[0].NeutronSubnetId,
+		Name:                 &lb.name,
+		VipSubnetCidrId:      &subnet.NeutronSubnetId,
 		VpcId:                &s.scope.VPC().Id,
 		AvailabilityZoneList: zones,
-		Publicip:             publicipLoadbalancer,
+	}
+	if flavorID := lb.spec.L4FlavorID; flavorID != "" {
+		if err := s.validateL4Flavor(ctx, flavorID); err != nil {
+			return err
+		}
+		loadbalancerbody.L4FlavorId = ptr.To(flavorID)
+	}
+	// a load balancer deleted out of band keeps the address of the endpoint, which is immutable
+	host := s.endpointHost(lb)
+	if lb.status().Id == "" {
+		host = ""
+	}
+	if lb.scheme() == infrav1alpha1.ELBSchemeInternetFacing {
+		if eipSpec := lb.spec.EIP; eipSpec != nil && eipSpec.ID != "" {
+			loadbalancerbody.PublicipIds = &[]string{eipSpec.ID}
+		} else if host != "" {
+			publicIpId, err := s.findPublicIpByAddress(ctx, host)
+			if err != nil {
+				return err
+			}
+			loadbalancerbody.PublicipIds = &[]string{publicIpId}
+		} else {
+			loadbalancerbody.Publicip = s.publicIpOption(lb)
+		}
+	} else if host != "" {
+		loadbalancerbody.VipAddress = ptr.To(host)
+	}
+	if s.scope.VPC().IPv6Enabled {
+		ipv6Subnet := subnet
+		if !subnet.IsIPv6 {
+			ipv6Subnets := s.scope.Subnets().FilterIPv6()
+			if len(ipv6Subnets) == 0 {
+				return errors.New("IPv6 is enabled but no subnet has IPv6 enabled")
+			}
+			ipv6Subnet = &ipv6Subnets[0]
+		}
+		loadbalancerbody.Ipv6VipVirsubnetId = ptr.To(ipv6Subnet.Id)
 	}
 	request.Body = &elbmodel.CreateLoadBalancerRequestBody{
 		Loadbalancer: loadbalancerbody,
 	}
 	klog.Infof("Create load balancer request: %v", request)
-	response, err := s.elbClient.CreateLoadBalancer(request)
+	response, err := s.elbClient.CreateLoadBalancer(ctx, request)
 	if err != nil {
+		s.scope.Warningf(err, "FailedCreateLoadBalancer", "Failed to create load balancer %s", lb.name)
 		return err
 	}
 	klog.Infof("Create load balancer response: %v", response)
+	s.scope.Eventf("SuccessfulCreateLoadBalancer", "Created load balancer %s (%s)", lb.name, ptr.Deref(response.LoadbalancerId, ""))
 	return nil
 }
 
-func (s *Service) deleteLoadBalancer(id string) error {
+// endpointHost returns the host of the control plane endpoint served by the load balancer.
+func (s *Service) endpointHost(lb *loadBalancer) string {
+	if lb.secondary {
+		return s.scope.HCCluster.Status.InternalControlPlaneEndpoint.Host
+	}
+	return s.scope.HCCluster.Spec.ControlPlaneEndpoint.Host
+}
+
+// findPublicIpByAddress returns the ID of the EIP with the given address.
+func (s *Service) findPublicIpByAddress(ctx context.Context, address string) (string, error) {
+	response, err := s.eipClient.ListPublicips(ctx, &eipmodel.ListPublicipsRequest{
+		PublicIpAddress: &[]string{address},
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to list public ips with address %s", address)
+	}
+	if response.Publicips == nil || len(*response.Publicips) == 0 || (*response.Publicips)[0].Id == nil {
+		return "", errors.Wrapf(errEndpointAddressNotFound, "public ip %s", address)
+	}
+	return *(*response.Publicips)[0].Id, nil
+}
+
+func (s *Service) deleteLoadBalancer(ctx context.Context, id string) error {
 	request := &elbmodel.DeleteLoadBalancerRequest{
 		LoadbalancerId: id,
 	}
 
-	_, err := s.elbClient.DeleteLoadBalancer(request)
-	return err
+	_, err := s.elbClient.DeleteLoadBalancer(ctx, request)
+	if err != nil {
+		s.scope.Warningf(err, "FailedDeleteLoadBalancer", "Failed to delete load balancer %s", id)
+		return err
+	}
+	s.scope.Eventf("SuccessfulDeleteLoadBalancer", "Deleted load balancer %s", id)
+	return nil
 }
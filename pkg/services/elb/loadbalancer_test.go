@@ -0,0 +1,224 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elb
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	. "github.com/onsi/gomega"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	structuraldefaulting "k8s.io/apiextensions-apiserver/pkg/apiserver/schema/defaulting"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
+)
+
+// clusterCRDFile is the generated CRD of the HuaweiCloudCluster, whose defaults are set by the API server.
+const clusterCRDFile = "../../../config/crd/bases/infrastructure.cluster.x-k8s.io_huaweicloudclusters.yaml"
+
+// defaultedClusterSpec returns the cluster spec of the YAML manifest with the defaults of the CRD schema,
+// as the API server would store it.
+func defaultedClusterSpec(t *testing.T, manifest string) infrav1alpha1.HuaweiCloudClusterSpec {
+	t.Helper()
+	data, err := os.ReadFile(clusterCRDFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.Unmarshal(data, crd); err != nil {
+		t.Fatal(err)
+	}
+	var schema *apiextensionsv1.JSONSchemaProps
+	for _, version := range crd.Spec.Versions {
+		if version.Name == infrav1alpha1.GroupVersion.Version {
+			spec := version.Schema.OpenAPIV3Schema.Properties["spec"]
+			schema = &spec
+		}
+	}
+	if schema == nil {
+		t.Fatalf("version %s not found in %s", infrav1alpha1.GroupVersion.Version, clusterCRDFile)
+	}
+	internalSchema := &apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(schema, internalSchema, nil); err != nil {
+		t.Fatal(err)
+	}
+	structural, err := structuralschema.NewStructural(internalSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var spec any
+	if err := yaml.Unmarshal([]byte(manifest), &spec); err != nil {
+		t.Fatal(err)
+	}
+	structuraldefaulting.Default(spec, structural)
+	defaulted, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterSpec := infrav1alpha1.HuaweiCloudClusterSpec{}
+	if err := json.Unmarshal(defaulted, &clusterSpec); err != nil {
+		t.Fatal(err)
+	}
+	return clusterSpec
+}
+
+func TestValidateLoadBalancers(t *testing.T) {
+	tests := []struct {
+		name       string
+		manifest   string
+		wantErr    string
+		wantScheme []infrav1alpha1.ELBScheme
+	}{
+		{
+			name:       "defaulted secondary load balancer",
+			manifest:   "secondaryControlPlaneLoadBalancer: {}",
+			wantScheme: []infrav1alpha1.ELBScheme{infrav1alpha1.ELBSchemeInternetFacing, infrav1alpha1.ELBSchemeInternal},
+		},
+		{
+			name: "internal secondary load balancer",
+			manifest: `
+controlPlaneLoadBalancer:
+  scheme: internet-facing
+secondaryControlPlaneLoadBalancer:
+  scheme: internal`,
+			wantScheme: []infrav1alpha1.ELBScheme{infrav1alpha1.ELBSchemeInternetFacing, infrav1alpha1.ELBSchemeInternal},
+		},
+		{
+			name:       "internal primary load balancer only",
+			manifest:   "controlPlaneLoadBalancer: {scheme: internal}",
+			wantScheme: []infrav1alpha1.ELBScheme{infrav1alpha1.ELBSchemeInternal},
+		},
+		{
+			name:     "internet-facing secondary load balancer",
+			manifest: "secondaryControlPlaneLoadBalancer: {scheme: internet-facing}",
+			wantErr:  "the secondary control plane load balancer must be internal",
+		},
+		{
+			name: "internal primary load balancer with a secondary load balancer",
+			manifest: `
+controlPlaneLoadBalancer:
+  scheme: internal
+secondaryControlPlaneLoadBalancer: {}`,
+			wantErr: "the control plane load balancer must be internet-facing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := &Service{
+				scope: &scope.ClusterScope{
+					Cluster: &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+					HCCluster: &infrav1alpha1.HuaweiCloudCluster{
+						ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+						Spec:       defaultedClusterSpec(t, tt.manifest),
+					},
+				},
+			}
+
+			loadBalancers := s.loadBalancers()
+			err := s.validateLoadBalancers(loadBalancers)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			var schemes []infrav1alpha1.ELBScheme
+			for _, lb := range loadBalancers {
+				schemes = append(schemes, lb.scheme())
+			}
+			g.Expect(schemes).To(Equal(tt.wantScheme))
+		})
+	}
+}
+
+func TestSelectAvailabilityZones(t *testing.T) {
+	zoneSets := [][]elbmodel.AvailabilityZone{
+		{
+			{Code: "ap-southeast-1a", State: "ACTIVE"},
+			{Code: "ap-southeast-1b", State: "INACTIVE"},
+			{Code: "ap-southeast-1c", State: "ACTIVE"},
+			{Code: "ap-southeast-1d", State: "ACTIVE"},
+		},
+		{
+			{Code: "ap-southeast-1e", State: "ACTIVE"},
+			{Code: "ap-southeast-1f", State: "ACTIVE"},
+		},
+	}
+	tests := []struct {
+		name     string
+		zoneSets [][]elbmodel.AvailabilityZone
+		desired  []string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "first two active zones by default",
+			zoneSets: zoneSets,
+			want:     []string{"ap-southeast-1a", "ap-southeast-1c"},
+		},
+		{
+			name:     "single active zone by default",
+			zoneSets: [][]elbmodel.AvailabilityZone{{{Code: "ap-southeast-1a", State: "ACTIVE"}}},
+			want:     []string{"ap-southeast-1a"},
+		},
+		{
+			name:     "desired zones of the first set",
+			zoneSets: zoneSets,
+			desired:  []string{"ap-southeast-1d", "ap-southeast-1a"},
+			want:     []string{"ap-southeast-1d", "ap-southeast-1a"},
+		},
+		{
+			name:     "desired zones of another set",
+			zoneSets: zoneSets,
+			desired:  []string{"ap-southeast-1f"},
+			want:     []string{"ap-southeast-1f"},
+		},
+		{
+			name:     "inactive desired zone",
+			zoneSets: zoneSets,
+			desired:  []string{"ap-southeast-1b"},
+			wantErr:  true,
+		},
+		{
+			name:     "desired zones of different sets",
+			zoneSets: zoneSets,
+			desired:  []string{"ap-southeast-1a", "ap-southeast-1e"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			zones, err := selectAvailabilityZones(tt.zoneSets, tt.desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(zones).To(Equal(tt.want))
+		})
+	}
+}
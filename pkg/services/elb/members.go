@@ -0,0 +1,134 @@
+package elb
+
+import (
+	"context"
+	"net"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	elbmodel "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/elb/v3/model"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
+)
+
+// RegisterInstance adds the instance as a member of the pools of the control plane load balancers.
+// On a dual-stack VPC, the IPv6 address of the instance is registered too.
+func (s *Service) RegisterInstance(ctx context.Context, instance *infrav1alpha1.Instance) error {
+	if instance.PrivateIP == nil || *instance.PrivateIP == "" {
+		return errors.Errorf("instance %s has no private IP", instance.ID)
+	}
+
+	for _, address := range s.memberAddresses(instance) {
+		subnetId, err := s.memberSubnet(address)
+		if err != nil {
+			return err
+		}
+
+		for _, lb := range s.loadBalancers() {
+			for _, pool := range lb.status().Pools {
+				members, err := s.describePoolMembersByAddress(ctx, pool.Id, address)
+				if err != nil {
+					return errors.Wrapf(err, "failed to list members of pool %s", pool.Id)
+				}
+				if len(members) > 0 {
+					continue
+				}
+
+				response, err := s.elbClient.CreateMember(ctx, &elbmodel.CreateMemberRequest{
+					PoolId: pool.Id,
+					Body: &elbmodel.CreateMemberRequestBody{
+						Member: &elbmodel.CreateMemberOption{
+							Address:      address,
+							ProtocolPort: ptr.To(pool.Port),
+							SubnetCidrId: ptr.To(subnetId),
+						},
+					},
+				})
+				if err != nil {
+					s.scope.Warningf(err, "FailedRegisterMember", "Failed to register instance %s with pool %s of load balancer %s", instance.ID, pool.Id, lb.name)
+					return errors.Wrapf(err, "failed to register instance %s with pool %s of load balancer %s", instance.ID, pool.Id, lb.name)
+				}
+				klog.Infof("Registered address %s of instance %s as member %s of pool %s", address, instance.ID, response.Member.Id, pool.Id)
+				s.scope.Eventf("SuccessfulRegisterMember", "Registered instance %s as member %s of pool %s", instance.ID, response.Member.Id, pool.Id)
+			}
+		}
+	}
+	return nil
+}
+
+// DeregisterInstance removes the instance from the pools of the control plane load balancers.
+func (s *Service) DeregisterInstance(ctx context.Context, instance *infrav1alpha1.Instance) error {
+	for _, address := range s.memberAddresses(instance) {
+		for _, lb := range s.loadBalancers() {
+			for _, pool := range lb.status().Pools {
+				members, err := s.describePoolMembersByAddress(ctx, pool.Id, address)
+				if err != nil {
+					return errors.Wrapf(err, "failed to list members of pool %s", pool.Id)
+				}
+				for _, member := range members {
+					_, err := s.elbClient.DeleteMember(ctx, &elbmodel.DeleteMemberRequest{PoolId: pool.Id, MemberId: member.Id})
+					if err != nil {
+						s.scope.Warningf(err, "FailedDeregisterMember", "Failed to deregister instance %s from pool %s of load balancer %s", instance.ID, pool.Id, lb.name)
+						return errors.Wrapf(err, "failed to deregister instance %s from pool %s of load balancer %s", instance.ID, pool.Id, lb.name)
+					}
+					klog.Infof("Deregistered address %s of instance %s as member %s of pool %s", address, instance.ID, member.Id, pool.Id)
+					s.scope.Eventf("SuccessfulDeregisterMember", "Deregistered instance %s as member %s of pool %s", instance.ID, member.Id, pool.Id)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// memberAddresses returns the addresses of the instance registered with the pools: the private IPv4 address,
+// and the IPv6 address when the pools are dual-stack.
+func (s *Service) memberAddresses(instance *infrav1alpha1.Instance) []string {
+	var addresses []string
+	if instance.PrivateIP != nil && *instance.PrivateIP != "" {
+		addresses = append(addresses, *instance.PrivateIP)
+	}
+	if s.scope.VPC().IPv6Enabled && instance.IPv6Address != nil && *instance.IPv6Address != "" {
+		addresses = append(addresses, *instance.IPv6Address)
+	}
+	return addresses
+}
+
+func (s *Service) describePoolMembersByAddress(ctx context.Context, poolId, address string) ([]elbmodel.Member, error) {
+	response, err := s.elbClient.ListMembers(ctx, &elbmodel.ListMembersRequest{
+		PoolId:  poolId,
+		Address: &[]string{address},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if response.Members == nil {
+		return nil, nil
+	}
+	return *response.Members, nil
+}
+
+// memberSubnet returns the subnet of the cluster containing the address, the IPv6 subnet for an IPv6 address.
+func (s *Service) memberSubnet(address string) (string, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return "", errors.Errorf("invalid member address %q", address)
+	}
+	for _, subnet := range s.scope.Subnets() {
+		if ip.To4() == nil {
+			if subnet.NeutronSubnetIdV6 != "" && cidrContains(subnet.IPv6CidrBlock, ip) {
+				return subnet.NeutronSubnetIdV6, nil
+			}
+			continue
+		}
+		if cidrContains(subnet.Cidr, ip) {
+			return subnet.NeutronSubnetId, nil
+		}
+	}
+	return "", errors.Errorf("no subnet of the cluster contains the address %s", address)
+}
+
+// cidrContains reports whether the CIDR block contains the IP, an invalid block contains no IP.
+func cidrContains(block string, ip net.IP) bool {
+	_, cidr, err := net.ParseCIDR(block)
+	return err == nil && cidr.Contains(ip)
+}
@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+
 	infrav1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
 )
@@ -8,7 +10,8 @@ import (
 // ECSInterface encapsulates the methods exposed to the machine
 // actuator.
 type ECSInterface interface {
-	InstanceIfExists(id *string) (*infrav1.Instance, error)
-	CreateInstance(scope *scope.MachineScope, userData []byte, userDataFormat string) (*infrav1.Instance, error)
-	TerminateInstance(id string) error
+	InstanceIfExists(ctx context.Context, id *string) (*infrav1.Instance, error)
+	CreateInstance(ctx context.Context, scope *scope.MachineScope, userData []byte, userDataFormat string) (*infrav1.Instance, error)
+	TerminateInstance(ctx context.Context, id string) (string, error)
+	DeleteJobStatus(ctx context.Context, jobId string) (done bool, err error)
 }
@@ -1,45 +1,64 @@
 package network
 
 import (
-	"fmt"
+	"context"
 
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	eipMdl "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/eip/v2/model"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
-	"sigs.k8s.io/cluster-api/util"
 )
 
-func (s *Service) allocatePublicIp() (string, error) {
+// allocatePublicIp allocates an EIP with a dedicated bandwidth, which defaults to 100 Mbit/s billed by traffic.
+func (s *Service) allocatePublicIp(ctx context.Context, name string, bandwidth *infrav1alpha1.BandwidthSpec) (string, error) {
+	chargeModes := eipMdl.GetCreatePublicipBandwidthOptionChargeModeEnum()
+	chargeMode := chargeModes.TRAFFIC
+	size := int32(100)
+	if bandwidth != nil {
+		if bandwidth.ChargeMode == infrav1alpha1.EIPChargeModeBandwidth {
+			chargeMode = chargeModes.BANDWIDTH
+		}
+		if bandwidth.Size > 0 {
+			size = bandwidth.Size
+		}
+	}
+
 	createPublicIpRequest := &eipMdl.CreatePublicipRequest{}
 	publicIpBody := &eipMdl.CreatePublicipOption{
-		Type: "5_bgp",
+		Type:  "5_bgp",
+		Alias: ptr.To(name),
 	}
 	bandwidthBody := &eipMdl.CreatePublicipBandwidthOption{
-		ChargeMode: ptr.To(eipMdl.GetCreatePublicipBandwidthOptionChargeModeEnum().TRAFFIC),
-		Name:       ptr.To(fmt.Sprintf("eip-%s", util.RandomString(4))),
+		ChargeMode: ptr.To(chargeMode),
+		Name:       ptr.To(name),
 		ShareType:  eipMdl.GetCreatePublicipBandwidthOptionShareTypeEnum().PER,
-		Size:       ptr.To[int32](100),
+		Size:       ptr.To(size),
 	}
 	createPublicIpRequest.Body = &eipMdl.CreatePublicipRequestBody{
 		Publicip:  publicIpBody,
 		Bandwidth: bandwidthBody,
 	}
-	createPublicIpResponse, err := s.eipClient.CreatePublicip(createPublicIpRequest)
+	createPublicIpResponse, err := s.eipClient.CreatePublicip(ctx, createPublicIpRequest)
 	if err != nil {
+		s.scope.Warningf(err, "FailedAllocateEIP", "Failed to allocate EIP %s", name)
 		return "", errors.Wrap(err, "failed to create public ip")
 	}
+	klog.Infof("Allocated public ip %s", *createPublicIpResponse.Publicip.Id)
+	s.scope.Eventf("SuccessfulAllocateEIP", "Allocated EIP %s", *createPublicIpResponse.Publicip.Id)
 	return *createPublicIpResponse.Publicip.Id, nil
 }
 
-func (s *Service) releasePublicIp(publicIpId string) error {
+func (s *Service) releasePublicIp(ctx context.Context, publicIpId string) error {
 	delPubIpReq := &eipMdl.DeletePublicipRequest{
 		PublicipId: publicIpId,
 	}
-	delPubIpRes, err := s.eipClient.DeletePublicip(delPubIpReq)
+	delPubIpRes, err := s.eipClient.DeletePublicip(ctx, delPubIpReq)
 	if err != nil {
+		s.scope.Warningf(err, "FailedReleaseEIP", "Failed to release EIP %s", publicIpId)
 		return errors.Wrapf(err, "failed to delete public ip %s", publicIpId)
 	}
 	klog.Infof("Delete public ip response: %v", delPubIpRes)
+	s.scope.Eventf("SuccessfulReleaseEIP", "Released EIP %s", publicIpId)
 	return nil
 }
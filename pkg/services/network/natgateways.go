@@ -1,20 +1,22 @@
 package network
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	natMdl "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/nat/v2/model"
-	vpcMdl "github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
-	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
-func (s *Service) reconcileNatGateways() error {
+func (s *Service) reconcileNatGateways(ctx context.Context) error {
 	klog.Info("Reconciling Nat Gateways")
 
 	if s.scope.VPC().Id == "" {
@@ -27,50 +29,133 @@ func (s *Service) reconcileNatGateways() error {
 		return nil
 	}
 
-	existing, err := s.describeNatGatewaysBySubnet()
+	existing, err := s.describeNatGatewaysBySubnet(ctx)
 	if err != nil {
 		return err
 	}
 
-	natGatewaysIds := make([]string, 0)
-	subnetIds := make([]string, 0)
+	existingIds := make([]string, 0, len(existing))
+	for _, natGatewayId := range existing {
+		existingIds = append(existingIds, natGatewayId)
+	}
+	snatRules, err := s.describeSnatRules(ctx, existingIds)
+	if err != nil {
+		return err
+	}
 
-	for _, subnet := range s.scope.Subnets() {
-		if ngw, ok := existing[subnet.Id]; ok {
-			natGatewaysIds = append(natGatewaysIds, ngw)
-			continue
+	natGateways := make([]infrav1alpha1.NatGateway, 0)
+	recorded := s.scope.Network().NatGateways
+	eipIds := s.scope.NatGateway().EIPIDs
+	for i, subnets := range s.natGatewaySubnets() {
+		natGateway := infrav1alpha1.NatGateway{}
+		for _, subnet := range subnets {
+			if natGatewayId, ok := existing[subnet.Id]; ok {
+				natGateway.Id = natGatewayId
+				natGateway.SubnetId = subnet.Id
+				break
+			}
+		}
+
+		if natGateway.Id == "" {
+			// set NatGatewayCreationStarted if the condition has never been set before
+			if !conditions.Has(s.scope.InfraCluster(), infrav1alpha1.NatGatewaysReadyCondition) {
+				conditions.MarkFalse(s.scope.InfraCluster(),
+					infrav1alpha1.NatGatewaysReadyCondition,
+					infrav1alpha1.NatGatewaysCreationStartedReason,
+					clusterv1.ConditionSeverityInfo, "")
+				if err := s.scope.PatchObject(); err != nil {
+					return errors.Wrap(err, "failed to patch conditions")
+				}
+			}
+			if i < len(recorded) && recorded[i].Id != "" {
+				klog.Warningf("NAT gateway %s no longer exists, re-creating it", recorded[i].Id)
+			}
+			natGateway.SubnetId = subnets[0].Id
+			natGateway.Id, err = s.createNatGateway(ctx, natGateway.SubnetId, i)
+			if err != nil {
+				return err
+			}
+		}
+
+		// All the SNAT rules of a NAT gateway share the same EIP.
+		if rules := snatRules[natGateway.Id]; len(rules) > 0 {
+			natGateway.EIPId = rules[0].FloatingIpId
+		}
+		for _, subnet := range subnets {
+			if slices.ContainsFunc(snatRules[natGateway.Id], func(rule natMdl.NatGatewaySnatRuleResponseBody) bool {
+				return rule.NetworkId == subnet.Id
+			}) {
+				continue
+			}
+			if natGateway.EIPId == "" {
+				if i < len(eipIds) {
+					natGateway.EIPId = eipIds[i]
+				} else {
+					natGateway.EIPId, err = s.allocatePublicIp(ctx, fmt.Sprintf("%s-nat-%d", s.scope.ClusterName(), i), s.scope.NatGateway().Bandwidth)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			// create SNAT rules to access the Internet
+			if err := s.createSnatRule(ctx, natGateway.Id, natGateway.EIPId, subnet.Id); err != nil {
+				return err
+			}
 		}
-		subnetIds = append(subnetIds, subnet.Id)
+		natGateways = append(natGateways, natGateway)
 	}
 
-	natGatewaysIps, err := s.getNatGatewaysIps(natGatewaysIds)
+	// The NAT gateways IPs are used as source of ingress rules by the security groups,
+	// which are reconciled afterwards, so they have to include the created NAT gateways.
+	natGatewaysIds := make([]string, 0, len(natGateways))
+	for _, natGateway := range natGateways {
+		natGatewaysIds = append(natGatewaysIds, natGateway.Id)
+	}
+	snatRules, err = s.describeSnatRules(ctx, natGatewaysIds)
 	if err != nil {
 		return err
 	}
+	natGatewaysIps := make([]string, 0, len(natGateways))
+	for i := range natGateways {
+		if rules := snatRules[natGateways[i].Id]; len(rules) > 0 {
+			natGateways[i].EIPAddress = rules[0].FloatingIpAddress
+			natGatewaysIps = append(natGatewaysIps, rules[0].FloatingIpAddress)
+		}
+	}
+	// keep the order stable, the IPs are compared with the security group rules
+	sort.Strings(natGatewaysIps)
 
-	s.scope.SetNatGatewaysIPs(natGatewaysIps)
+	s.scope.SetNatGateways(natGateways)
+	s.scope.SetNatGatewaysIPs(slices.Compact(natGatewaysIps))
+	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.NatGatewaysReadyCondition)
+	return nil
+}
 
-	if len(subnetIds) > 0 {
-		// set NatGatewayCreationStarted if the condition has never been set before
-		if !conditions.Has(s.scope.InfraCluster(), infrav1alpha1.NatGatewaysReadyCondition) {
-			conditions.MarkFalse(s.scope.InfraCluster(),
-				infrav1alpha1.NatGatewaysReadyCondition,
-				infrav1alpha1.NatGatewaysCreationStartedReason,
-				clusterv1.ConditionSeverityInfo, "")
-			if err := s.scope.PatchObject(); err != nil {
-				return errors.Wrap(err, "failed to patch conditions")
-			}
+// natGatewaySubnets groups the subnets of the cluster VPC by the NAT gateway providing their outbound access,
+// either a single group or a group per availability zone. The subnets without an availability zone share a group.
+func (s *Service) natGatewaySubnets() [][]infrav1alpha1.SubnetSpec {
+	groups := make([][]infrav1alpha1.SubnetSpec, 0)
+	zones := map[string]int{}
+	for _, subnet := range s.scope.Subnets() {
+		if subnet.VpcId != "" && subnet.VpcId != s.scope.VPC().Id {
+			continue
 		}
-		err := s.createNatGateways(subnetIds)
-		if err != nil {
-			return err
+		zone := subnet.AvailabilityZone
+		if s.scope.NatGateway().Placement == infrav1alpha1.NatGatewayPlacementSingle {
+			zone = ""
+		}
+		i, ok := zones[zone]
+		if !ok {
+			i = len(groups)
+			zones[zone] = i
+			groups = append(groups, nil)
 		}
-		conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.NatGatewaysReadyCondition)
+		groups[i] = append(groups[i], subnet)
 	}
-	return nil
+	return groups
 }
 
-func (s *Service) deleteNatGateways() error {
+func (s *Service) deleteNatGateways(ctx context.Context) error {
 	if s.scope.VPC().Id == "" {
 		klog.Infof("VPC ID is empty")
 		return nil
@@ -78,65 +163,73 @@ func (s *Service) deleteNatGateways() error {
 	listNatGatewaysRequest := &natMdl.ListNatGatewaysRequest{
 		RouterId: &s.scope.VPC().Id,
 	}
-	listNatGatewaysResponse, err := s.natClient.ListNatGateways(listNatGatewaysRequest)
+	listNatGatewaysResponse, err := s.natClient.ListNatGateways(ctx, listNatGatewaysRequest)
 	if err != nil {
 		return errors.Wrap(err, "failed to list nat gateways")
 	}
 
 	for _, natGateway := range *listNatGatewaysResponse.NatGateways {
-		if err := s.deleteNatGatewaysExistingRule(natGateway.Id); err != nil {
+		if err := s.deleteNatGatewaysExistingRule(ctx, natGateway.Id); err != nil {
 			return err
 		}
 		deleteNatGatewayRequest := &natMdl.DeleteNatGatewayRequest{
 			NatGatewayId: natGateway.Id,
 		}
-		_, err = s.natClient.DeleteNatGateway(deleteNatGatewayRequest)
+		_, err = s.natClient.DeleteNatGateway(ctx, deleteNatGatewayRequest)
 		if err != nil {
+			s.scope.Warningf(err, "FailedDeleteNATGateway", "Failed to delete NAT gateway %s", natGateway.Id)
 			return errors.Wrap(err, "failed to delete nat gateways")
 		}
 		klog.Infof("Delete Nat Gateway %s", natGateway.Id)
+		s.scope.Eventf("SuccessfulDeleteNATGateway", "Deleted NAT gateway %s", natGateway.Id)
 	}
+	s.scope.SetNatGateways(nil)
+	s.scope.SetNatGatewaysIPs(nil)
 	return nil
 }
 
-func (s *Service) createNatGateways(subnetIds []string) (err error) {
-	for _, subnetId := range subnetIds {
-		showSubnetRequest := &vpcMdl.ShowSubnetRequest{SubnetId: subnetId}
-		showSubnetResponse, err := s.vpcClient.ShowSubnet(showSubnetRequest)
-		if err != nil {
-			return errors.Wrap(err, "failed to find subnet")
-		}
-		if showSubnetResponse.Subnet.VpcId != s.scope.VPC().Id {
-			continue
-		}
-		createNatGatewayRequest := &natMdl.CreateNatGatewayRequest{}
-		createNatGatewayRequest.Body = &natMdl.CreateNatGatewayRequestBody{
-			NatGateway: &natMdl.CreateNatGatewayOption{
-				Name:              fmt.Sprintf("nat-%s", util.RandomString(4)),
-				RouterId:          s.scope.VPC().Id,
-				Spec:              natMdl.GetCreateNatGatewayOptionSpecEnum().E_1,
-				InternalNetworkId: subnetId,
-			},
-		}
-		createNatGatewayResponse, err := s.natClient.CreateNatGateway(createNatGatewayRequest)
-		if err != nil {
-			return errors.Wrap(err, "failed to create nat gateway")
-		}
-		// allocate EIP to Nat Gateway
-		publicIpId, err := s.allocatePublicIp()
-		if err != nil {
-			return err
-		}
-		// create SNAT rules to access the Internet
-		if err = s.createSnatRule(createNatGatewayResponse.NatGateway.Id, publicIpId, subnetId); err != nil {
-			return err
-		}
-		klog.Infof("Created Nat Gateway %s", createNatGatewayResponse.NatGateway.Id)
+func (s *Service) createNatGateway(ctx context.Context, subnetId string, index int) (string, error) {
+	spec, err := natGatewaySpec(s.scope.NatGateway().Size)
+	if err != nil {
+		return "", err
 	}
-	return nil
+	createNatGatewayRequest := &natMdl.CreateNatGatewayRequest{}
+	createNatGatewayRequest.Body = &natMdl.CreateNatGatewayRequestBody{
+		NatGateway: &natMdl.CreateNatGatewayOption{
+			Name:              fmt.Sprintf("%s-nat-%d", s.scope.ClusterName(), index),
+			RouterId:          s.scope.VPC().Id,
+			Spec:              spec,
+			InternalNetworkId: subnetId,
+		},
+	}
+	createNatGatewayResponse, err := s.natClient.CreateNatGateway(ctx, createNatGatewayRequest)
+	if err != nil {
+		s.scope.Warningf(err, "FailedCreateNATGateway", "Failed to create NAT gateway in subnet %s", subnetId)
+		return "", errors.Wrap(err, "failed to create nat gateway")
+	}
+	klog.Infof("Created Nat Gateway %s", createNatGatewayResponse.NatGateway.Id)
+	s.scope.Eventf("SuccessfulCreateNATGateway", "Created NAT gateway %s in subnet %s", createNatGatewayResponse.NatGateway.Id, subnetId)
+	return createNatGatewayResponse.NatGateway.Id, nil
 }
 
-func (s *Service) createSnatRule(natGatewayId, publicIpId, subnetId string) error {
+// natGatewaySpec converts the NAT gateway size to the HuaweiCloud NAT gateway specification.
+func natGatewaySpec(size infrav1alpha1.NatGatewaySize) (natMdl.CreateNatGatewayOptionSpec, error) {
+	specs := natMdl.GetCreateNatGatewayOptionSpecEnum()
+	switch size {
+	case "", infrav1alpha1.NatGatewaySizeSmall:
+		return specs.E_1, nil
+	case infrav1alpha1.NatGatewaySizeMedium:
+		return specs.E_2, nil
+	case infrav1alpha1.NatGatewaySizeLarge:
+		return specs.E_3, nil
+	case infrav1alpha1.NatGatewaySizeExtraLarge:
+		return specs.E_4, nil
+	default:
+		return natMdl.CreateNatGatewayOptionSpec{}, errors.Errorf("unsupported nat gateway size %q", size)
+	}
+}
+
+func (s *Service) createSnatRule(ctx context.Context, natGatewayId, publicIpId, subnetId string) error {
 	snatRequest := &natMdl.CreateNatGatewaySnatRuleRequest{}
 	snatRequest.Body = &natMdl.CreateNatGatewaySnatRuleRequestOption{
 		SnatRule: &natMdl.CreateNatGatewaySnatRuleOption{
@@ -145,18 +238,23 @@ func (s *Service) createSnatRule(natGatewayId, publicIpId, subnetId string) erro
 			NetworkId:    &subnetId,
 		},
 	}
-	_, err := s.natClient.CreateNatGatewaySnatRule(snatRequest)
+	response, err := s.natClient.CreateNatGatewaySnatRule(ctx, snatRequest)
 	if err != nil {
+		s.scope.Warningf(err, "FailedCreateSNATRule", "Failed to create SNAT rule of NAT gateway %s for subnet %s", natGatewayId, subnetId)
 		return errors.Wrap(err, "failed to create nat gateway snat rule")
 	}
+	s.scope.Eventf("SuccessfulCreateSNATRule", "Created SNAT rule %s of NAT gateway %s for subnet %s", response.SnatRule.Id, natGatewayId, subnetId)
 	return nil
 }
 
-func (s *Service) deleteNatGatewaysExistingRule(natGatewayId string) error {
+func (s *Service) deleteNatGatewaysExistingRule(ctx context.Context, natGatewayId string) error {
+	// The EIPs can be shared by several rules, they are released once all the rules are deleted.
+	publicIpIds := make([]string, 0)
+
 	listNatGatewaySnatRulesRequest := &natMdl.ListNatGatewaySnatRulesRequest{
 		NatGatewayId: ptr.To([]string{natGatewayId}),
 	}
-	listNatGatewaySnatRulesResponse, err := s.natClient.ListNatGatewaySnatRules(listNatGatewaySnatRulesRequest)
+	listNatGatewaySnatRulesResponse, err := s.natClient.ListNatGatewaySnatRules(ctx, listNatGatewaySnatRulesRequest)
 	if err != nil {
 		return errors.Wrap(err, "failed to list nat gateway snat rule")
 	}
@@ -166,21 +264,20 @@ func (s *Service) deleteNatGatewaysExistingRule(natGatewayId string) error {
 			NatGatewayId: natGatewayId,
 			SnatRuleId:   snatRule.Id,
 		}
-		_, err = s.natClient.DeleteNatGatewaySnatRule(deleteNatGatewaySnatRuleRequest)
+		_, err = s.natClient.DeleteNatGatewaySnatRule(ctx, deleteNatGatewaySnatRuleRequest)
 		if err != nil {
+			s.scope.Warningf(err, "FailedDeleteSNATRule", "Failed to delete SNAT rule %s of NAT gateway %s", snatRule.Id, natGatewayId)
 			return errors.Wrap(err, "failed to delete nat gateway snat rule")
 		}
 		klog.Infof("Deleted NatGateway SnatRule %s", snatRule.Id)
-
-		if err := s.releasePublicIp(snatRule.FloatingIpId); err != nil {
-			return err
-		}
+		s.scope.Eventf("SuccessfulDeleteSNATRule", "Deleted SNAT rule %s of NAT gateway %s", snatRule.Id, natGatewayId)
+		publicIpIds = append(publicIpIds, strings.Split(snatRule.FloatingIpId, ",")...)
 	}
 
 	listNatGatewayDnatRulesRequest := &natMdl.ListNatGatewayDnatRulesRequest{
 		NatGatewayId: ptr.To([]string{natGatewayId}),
 	}
-	listNatGatewayDnatRulesResponse, err := s.natClient.ListNatGatewayDnatRules(listNatGatewayDnatRulesRequest)
+	listNatGatewayDnatRulesResponse, err := s.natClient.ListNatGatewayDnatRules(ctx, listNatGatewayDnatRulesRequest)
 	if err != nil {
 		return errors.Wrap(err, "failed to list nat gateway snat rule")
 	}
@@ -190,24 +287,34 @@ func (s *Service) deleteNatGatewaysExistingRule(natGatewayId string) error {
 			NatGatewayId: natGatewayId,
 			DnatRuleId:   dnatRule.Id,
 		}
-		_, err = s.natClient.DeleteNatGatewayDnatRule(deleteNatGatewayDnatRuleRequest)
+		_, err = s.natClient.DeleteNatGatewayDnatRule(ctx, deleteNatGatewayDnatRuleRequest)
 		if err != nil {
+			s.scope.Warningf(err, "FailedDeleteDNATRule", "Failed to delete DNAT rule %s of NAT gateway %s", dnatRule.Id, natGatewayId)
 			return errors.Wrap(err, "failed to delete nat gateway dnat rule")
 		}
 		klog.Infof("Deleted NatGateway DnatRule %s", dnatRule.Id)
+		s.scope.Eventf("SuccessfulDeleteDNATRule", "Deleted DNAT rule %s of NAT gateway %s", dnatRule.Id, natGatewayId)
+		publicIpIds = append(publicIpIds, dnatRule.FloatingIpId)
+	}
 
-		if err := s.releasePublicIp(dnatRule.FloatingIpId); err != nil {
+	sort.Strings(publicIpIds)
+	for _, publicIpId := range slices.Compact(publicIpIds) {
+		// the EIPs provided by the user are not owned by the cluster
+		if slices.Contains(s.scope.NatGateway().EIPIDs, publicIpId) {
+			continue
+		}
+		if err := s.releasePublicIp(ctx, publicIpId); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *Service) describeNatGatewaysBySubnet() (map[string]string, error) {
+func (s *Service) describeNatGatewaysBySubnet(ctx context.Context) (map[string]string, error) {
 	request := &natMdl.ListNatGatewaysRequest{
 		RouterId: &s.scope.VPC().Id,
 	}
-	response, err := s.natClient.ListNatGateways(request)
+	response, err := s.natClient.ListNatGateways(ctx, request)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list nat gateways")
 	}
@@ -218,17 +325,21 @@ func (s *Service) describeNatGatewaysBySubnet() (map[string]string, error) {
 	return gatewaysIds, nil
 }
 
-func (s *Service) getNatGatewaysIps(natGatewayIds []string) ([]string, error) {
+// describeSnatRules returns the SNAT rules of the NAT gateways by NAT gateway ID.
+func (s *Service) describeSnatRules(ctx context.Context, natGatewayIds []string) (map[string][]natMdl.NatGatewaySnatRuleResponseBody, error) {
+	snatRules := map[string][]natMdl.NatGatewaySnatRuleResponseBody{}
+	if len(natGatewayIds) == 0 {
+		return snatRules, nil
+	}
 	request := &natMdl.ListNatGatewaySnatRulesRequest{
 		NatGatewayId: &natGatewayIds,
 	}
-	response, err := s.natClient.ListNatGatewaySnatRules(request)
+	response, err := s.natClient.ListNatGatewaySnatRules(ctx, request)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list nat gateway snat rule")
 	}
-	nateGatewaysIps := make([]string, 0)
 	for _, snat := range *response.SnatRules {
-		nateGatewaysIps = append(nateGatewaysIps, snat.FloatingIpAddress)
+		snatRules[snat.NatGatewayId] = append(snatRules[snat.NatGatewayId], snat)
 	}
-	return nateGatewaysIps, nil
+	return snatRules, nil
 }
@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"testing"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
+	. "github.com/onsi/gomega"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
+)
+
+func newTestService(networkSpec infrav1alpha1.NetworkSpec) *Service {
+	return &Service{
+		scope: &scope.ClusterScope{
+			HCCluster: &infrav1alpha1.HuaweiCloudCluster{
+				Spec: infrav1alpha1.HuaweiCloudClusterSpec{NetworkSpec: networkSpec},
+			},
+		},
+	}
+}
+
+func TestNatGatewaySubnets(t *testing.T) {
+	subnets := infrav1alpha1.Subnets{
+		{Id: "subnet-a1", VpcId: "vpc", AvailabilityZone: "az-a"},
+		{Id: "subnet-b1", VpcId: "vpc", AvailabilityZone: "az-b"},
+		{Id: "subnet-a2", VpcId: "vpc", AvailabilityZone: "az-a"},
+		{Id: "subnet-none", VpcId: "vpc"},
+		{Id: "subnet-other-vpc", VpcId: "other", AvailabilityZone: "az-a"},
+	}
+	tests := []struct {
+		name      string
+		placement infrav1alpha1.NatGatewayPlacement
+		want      [][]string
+	}{
+		{
+			name:      "per availability zone",
+			placement: infrav1alpha1.NatGatewayPlacementPerAvailabilityZone,
+			want:      [][]string{{"subnet-a1", "subnet-a2"}, {"subnet-b1"}, {"subnet-none"}},
+		},
+		{
+			name: "per availability zone by default",
+			want: [][]string{{"subnet-a1", "subnet-a2"}, {"subnet-b1"}, {"subnet-none"}},
+		},
+		{
+			name:      "single",
+			placement: infrav1alpha1.NatGatewayPlacementSingle,
+			want:      [][]string{{"subnet-a1", "subnet-b1", "subnet-a2", "subnet-none"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := newTestService(infrav1alpha1.NetworkSpec{
+				VPC:        infrav1alpha1.VPCSpec{Id: "vpc"},
+				Subnets:    subnets,
+				NatGateway: &infrav1alpha1.NatGatewaySpec{Placement: tt.placement},
+			})
+
+			var got [][]string
+			for _, group := range s.natGatewaySubnets() {
+				ids := []string{}
+				for _, subnet := range group {
+					ids = append(ids, subnet.Id)
+				}
+				got = append(got, ids)
+			}
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestSubnetSpecs(t *testing.T) {
+	subnets := []model.Subnet{
+		{Id: "subnet-1", AvailabilityZone: "az-a"},
+		{Id: "subnet-2", AvailabilityZone: "az-b"},
+		{Id: "subnet-3"},
+	}
+	tests := []struct {
+		name     string
+		recorded infrav1alpha1.Subnets
+		want     []string
+	}{
+		{
+			name: "new cluster",
+			want: []string{"subnet-1", "subnet-2", "subnet-3"},
+		},
+		{
+			name:     "recorded subnet is kept first",
+			recorded: infrav1alpha1.Subnets{{Id: "subnet-2"}},
+			want:     []string{"subnet-2", "subnet-1", "subnet-3"},
+		},
+		{
+			name:     "recorded subnet deleted",
+			recorded: infrav1alpha1.Subnets{{Id: "subnet-4"}},
+			want:     []string{"subnet-1", "subnet-2", "subnet-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := newTestService(infrav1alpha1.NetworkSpec{Subnets: tt.recorded})
+
+			specs := s.subnetSpecs(subnets)
+			ids := []string{}
+			for _, spec := range specs {
+				ids = append(ids, spec.Id)
+			}
+			g.Expect(ids).To(Equal(tt.want))
+			g.Expect(specs.FindByID("subnet-1").AvailabilityZone).To(Equal("az-a"))
+		})
+	}
+}
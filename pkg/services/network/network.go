@@ -1,18 +1,28 @@
 package network
 
 import (
+	"context"
+
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
-func (s *Service) ReconcileNetwork() error {
+func (s *Service) ReconcileNetwork(ctx context.Context) error {
 	klog.Infof("Reconciling network")
 
 	// VPC
-	if err := s.reconcileVPC(); err != nil {
+	if err := s.reconcileVPC(ctx); err != nil {
 		klog.Errorf("Failed to reconcile VPC: %v", err)
+		if errors.Is(err, errVPCNotFound) {
+			conditions.MarkFalse(s.scope.InfraCluster(),
+				infrav1alpha1.VpcReadyCondition,
+				infrav1alpha1.VpcNotFoundReason,
+				clusterv1.ConditionSeverityError, "VPC %s no longer exists", s.scope.VPC().Id)
+			return err
+		}
 		conditions.MarkFalse(s.scope.InfraCluster(),
 			infrav1alpha1.VpcReadyCondition,
 			infrav1alpha1.VpcReconciliationFailedReason,
@@ -22,7 +32,7 @@ func (s *Service) ReconcileNetwork() error {
 	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.VpcReadyCondition)
 
 	// Subnets
-	if err := s.reconcileSubnets(); err != nil {
+	if err := s.reconcileSubnets(ctx); err != nil {
 		klog.Errorf("Failed to reconcile Subnets: %v", err)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
@@ -35,7 +45,7 @@ func (s *Service) ReconcileNetwork() error {
 	// TODO: Routing tables
 
 	// NAT Gateways.
-	if err := s.reconcileNatGateways(); err != nil {
+	if err := s.reconcileNatGateways(ctx); err != nil {
 		klog.Errorf("Failed to reconcile NatGateways: %v", err)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
@@ -49,7 +59,7 @@ func (s *Service) ReconcileNetwork() error {
 	return nil
 }
 
-func (s *Service) DeleteNetwork() error {
+func (s *Service) DeleteNetwork(ctx context.Context) error {
 	klog.Infof("Deleting network")
 
 	// Delete Nat Gateways
@@ -61,7 +71,7 @@ func (s *Service) DeleteNetwork() error {
 	if err := s.scope.PatchObject(); err != nil {
 		return err
 	}
-	if err := s.deleteNatGateways(); err != nil {
+	if err := s.deleteNatGateways(ctx); err != nil {
 		klog.Errorf("Failed to delete NateGateways: %v", err)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
@@ -85,7 +95,7 @@ func (s *Service) DeleteNetwork() error {
 	if err := s.scope.PatchObject(); err != nil {
 		return err
 	}
-	if err := s.deleteSubnets(); err != nil {
+	if err := s.deleteSubnets(ctx); err != nil {
 		klog.Errorf("Failed to delete subnets: %v", err)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
@@ -111,7 +121,7 @@ func (s *Service) DeleteNetwork() error {
 	if err := s.scope.PatchObject(); err != nil {
 		return err
 	}
-	if err := s.deleteVPC(); err != nil {
+	if err := s.deleteVPC(ctx); err != nil {
 		klog.Errorf("Failed to delete VPC: %v", err)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
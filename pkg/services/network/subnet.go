@@ -1,15 +1,18 @@
 package network
 
 import (
+	"context"
+	"slices"
 	"strings"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 )
 
-func (s *Service) reconcileSubnets() error {
+func (s *Service) reconcileSubnets(ctx context.Context) error {
 	if s.scope.VPC().Id == "" {
 		return errors.New("VPC ID is empty")
 	}
@@ -18,12 +21,12 @@ func (s *Service) reconcileSubnets() error {
 	request := &model.ListSubnetsRequest{
 		VpcId: &s.scope.VPC().Id,
 	}
-	response, err := s.vpcClient.ListSubnets(request)
+	response, err := s.vpcClient.ListSubnets(ctx, request)
 	if err != nil {
 		return errors.Wrap(err, "failed to list subnets")
 	}
 
-	var subnet *model.Subnet
+	var subnets []model.Subnet
 	if len(*response.Subnets) == 0 {
 		createRequest := &model.CreateSubnetRequest{}
 		subnetbody := &model.CreateSubnetOption{
@@ -32,32 +35,36 @@ func (s *Service) reconcileSubnets() error {
 			VpcId:     s.scope.VPC().Id,
 			GatewayIp: "192.168.1.1",
 		}
+		if s.scope.VPC().IPv6Enabled {
+			subnetbody.Ipv6Enable = ptr.To(true)
+		}
 		createRequest.Body = &model.CreateSubnetRequestBody{
 			Subnet: subnetbody,
 		}
-		response, err := s.vpcClient.CreateSubnet(createRequest)
+		response, err := s.vpcClient.CreateSubnet(ctx, createRequest)
 		if err != nil {
+			s.scope.Warningf(err, "FailedCreateSubnet", "Failed to create subnet %s in VPC %s", subnetbody.Name, s.scope.VPC().Id)
 			return errors.Wrap(err, "failed to create subnet")
 		}
 
-		subnet = response.Subnet
 		klog.Infof("Subnet created, response: %v", response)
+		s.scope.Eventf("SuccessfulCreateSubnet", "Created subnet %s in VPC %s", response.Subnet.Id, response.Subnet.VpcId)
+		subnets = append(subnets, *response.Subnet)
 	} else {
-		subnet = &(*response.Subnets)[0]
-		klog.Infof("Subnet already exists")
+		klog.Infof("Subnets already exist")
+		for i := range *response.Subnets {
+			subnet := &(*response.Subnets)[i]
+			if s.scope.VPC().IPv6Enabled && !subnet.Ipv6Enable {
+				subnet, err = s.enableSubnetIPv6(ctx, subnet)
+				if err != nil {
+					return err
+				}
+			}
+			subnets = append(subnets, *subnet)
+		}
 	}
 
-	s.scope.SetSubnets([]infrav1alpha1.SubnetSpec{
-		{
-			Id:               subnet.Id,
-			Name:             subnet.Name,
-			Cidr:             subnet.Cidr,
-			GatewayIp:        subnet.GatewayIp,
-			VpcId:            subnet.VpcId,
-			NeutronNetworkId: subnet.NeutronNetworkId,
-			NeutronSubnetId:  subnet.NeutronSubnetId,
-		},
-	})
+	s.scope.SetSubnets(s.subnetSpecs(subnets))
 
 	// Persist the new default subnets to HCCluster
 	if err := s.scope.PatchObject(); err != nil {
@@ -68,7 +75,60 @@ func (s *Service) reconcileSubnets() error {
 	return nil
 }
 
-func (s *Service) deleteSubnets() error {
+// subnetSpecs converts the subnets of the VPC, the first recorded subnet is kept first as the machines
+// and the load balancer default to it.
+func (s *Service) subnetSpecs(subnets []model.Subnet) infrav1alpha1.Subnets {
+	specs := make(infrav1alpha1.Subnets, 0, len(subnets))
+	for _, subnet := range subnets {
+		specs = append(specs, infrav1alpha1.SubnetSpec{
+			Id:                subnet.Id,
+			Name:              subnet.Name,
+			Cidr:              subnet.Cidr,
+			GatewayIp:         subnet.GatewayIp,
+			VpcId:             subnet.VpcId,
+			AvailabilityZone:  subnet.AvailabilityZone,
+			NeutronNetworkId:  subnet.NeutronNetworkId,
+			NeutronSubnetId:   subnet.NeutronSubnetId,
+			IPv6CidrBlock:     subnet.CidrV6,
+			NeutronSubnetIdV6: subnet.NeutronSubnetIdV6,
+			IsIPv6:            subnet.Ipv6Enable,
+		})
+	}
+	if recorded := s.scope.Subnets(); len(recorded) > 0 {
+		slices.SortStableFunc(specs, func(a, b infrav1alpha1.SubnetSpec) int {
+			switch recorded[0].Id {
+			case a.Id:
+				return -1
+			case b.Id:
+				return 1
+			}
+			return 0
+		})
+	}
+	return specs
+}
+
+// enableSubnetIPv6 turns on IPv6 for an existing subnet, HuaweiCloud allocates its IPv6 CIDR block.
+func (s *Service) enableSubnetIPv6(ctx context.Context, subnet *model.Subnet) (*model.Subnet, error) {
+	request := &model.UpdateSubnetRequest{
+		VpcId:    subnet.VpcId,
+		SubnetId: subnet.Id,
+		Body: &model.UpdateSubnetRequestBody{
+			Subnet: &model.UpdateSubnetOption{
+				Name:       subnet.Name,
+				Ipv6Enable: ptr.To(true),
+			},
+		},
+	}
+	if _, err := s.vpcClient.UpdateSubnet(ctx, request); err != nil {
+		return nil, errors.Wrapf(err, "failed to enable IPv6 on subnet %s", subnet.Id)
+	}
+	klog.Infof("Enabled IPv6 on subnet %s", subnet.Id)
+
+	return s.FindSubnet(ctx, subnet.Id)
+}
+
+func (s *Service) deleteSubnets(ctx context.Context) error {
 	if s.scope.VPC().Id == "" {
 		klog.Infof("VPC ID is empty")
 		return nil
@@ -76,7 +136,7 @@ func (s *Service) deleteSubnets() error {
 	request := &model.ListSubnetsRequest{
 		VpcId: &s.scope.VPC().Id,
 	}
-	response, err := s.vpcClient.ListSubnets(request)
+	response, err := s.vpcClient.ListSubnets(ctx, request)
 	if err != nil {
 		if strings.Contains(err.Error(), "VPC.0202") {
 			klog.Infof("VPC not found")
@@ -90,22 +150,24 @@ func (s *Service) deleteSubnets() error {
 			VpcId:    subnet.VpcId,
 			SubnetId: subnet.Id,
 		}
-		response, err := s.vpcClient.DeleteSubnet(deleteRequest)
+		response, err := s.vpcClient.DeleteSubnet(ctx, deleteRequest)
 		if err != nil {
+			s.scope.Warningf(err, "FailedDeleteSubnet", "Failed to delete subnet %s", subnet.Id)
 			return errors.Wrapf(err, "failed to delete subnet %s", subnet.Id)
 		}
 		klog.Infof("subnet delete response: %v", response)
 		klog.Infof("Deleted subnet %s", subnet.Id)
+		s.scope.Eventf("SuccessfulDeleteSubnet", "Deleted subnet %s", subnet.Id)
 	}
 
 	return nil
 }
 
-func (s *Service) FindSubnet(subnetId string) (*model.Subnet, error) {
+func (s *Service) FindSubnet(ctx context.Context, subnetId string) (*model.Subnet, error) {
 	request := &model.ShowSubnetRequest{
 		SubnetId: subnetId,
 	}
-	response, err := s.vpcClient.ShowSubnet(request)
+	response, err := s.vpcClient.ShowSubnet(ctx, request)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to show subnet %s", subnetId)
 	}
@@ -1,9 +1,12 @@
 package network
 
 import (
+	"context"
+	"net/http"
 	"strings"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -11,9 +14,23 @@ import (
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
-func (s *Service) reconcileVPC() error {
+// errVPCNotFound is returned when the VPC of the cluster was deleted out of band. The VPC is not
+// re-created, the subnets, security groups and machines of the cluster were deleted with it.
+var errVPCNotFound = errors.New("VPC not found")
+
+func (s *Service) reconcileVPC(ctx context.Context) error {
 	// check if VPC exists, if not create it
 	if s.scope.VPC().Id != "" {
+		request := &model.ShowVpcRequest{
+			VpcId: s.scope.VPC().Id,
+		}
+		if _, err := s.vpcClient.ShowVpc(ctx, request); err != nil {
+			if ecserrors.StatusCode(err) == http.StatusNotFound {
+				s.scope.Warningf(err, "VPCNotFound", "VPC %s no longer exists", s.scope.VPC().Id)
+				return errors.Wrapf(errVPCNotFound, "VPC %s", s.scope.VPC().Id)
+			}
+			return errors.Wrapf(err, "failed to get VPC %s", s.scope.VPC().Id)
+		}
 		klog.Infof("VPC %s already exists", s.scope.VPC().Id)
 		return nil
 	}
@@ -29,13 +46,15 @@ func (s *Service) reconcileVPC() error {
 		Vpc: vpcbody,
 	}
 
-	createRes, err := s.vpcClient.CreateVpc(createRequest)
+	createRes, err := s.vpcClient.CreateVpc(ctx, createRequest)
 	if err != nil {
+		s.scope.Warningf(err, "FailedCreateVPC", "Failed to create VPC %s", nameVpc)
 		return errors.Wrap(err, "failed to create VPC")
 	}
 	vpc := createRes.Vpc
 	klog.Infof("VPC create response: %v", createRes)
 	klog.Infof("Created VPC %s", vpc.Id)
+	s.scope.Eventf("SuccessfulCreateVPC", "Created VPC %s", vpc.Id)
 
 	s.scope.VPC().Id = vpc.Id
 	s.scope.VPC().Name = vpc.Name
@@ -56,7 +75,7 @@ func (s *Service) reconcileVPC() error {
 	return nil
 }
 
-func (s *Service) deleteVPC() error {
+func (s *Service) deleteVPC(ctx context.Context) error {
 	if s.scope.VPC().Id == "" {
 		klog.Warning("VPC ID is empty")
 		return nil
@@ -65,15 +84,17 @@ func (s *Service) deleteVPC() error {
 	deleteRequest := &model.DeleteVpcRequest{
 		VpcId: s.scope.VPC().Id,
 	}
-	response, err := s.vpcClient.DeleteVpc(deleteRequest)
+	response, err := s.vpcClient.DeleteVpc(ctx, deleteRequest)
 	if err != nil {
 		if strings.Contains(err.Error(), "404") {
-			klog.Info("VPC already deleted", "vpcID", s.scope.VPC().Id)
+			klog.InfoS("VPC already deleted", "vpcID", s.scope.VPC().Id)
 			return nil
 		}
+		s.scope.Warningf(err, "FailedDeleteVPC", "Failed to delete VPC %s", s.scope.VPC().Id)
 		return errors.Wrapf(err, "failed to delete VPC %s", s.scope.VPC().Id)
 	}
 	klog.Infof("VPC delete response: %v", response)
 	klog.Infof("Deleted VPC %s", s.scope.VPC().Id)
+	s.scope.Eventf("SuccessfulDeleteVPC", "Deleted VPC %s", s.scope.VPC().Id)
 	return nil
 }
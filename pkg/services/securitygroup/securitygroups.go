@@ -1,163 +1,601 @@
 package securitygroup
 
 import (
+	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"slices"
 
 	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/ecserrors"
 	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/ptr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/conditions"
 )
 
-func (s *Service) ReconcileSecurityGroups() error {
-	klog.Info("Reconciling security groups")
+const (
+	anyIPv4CidrBlock = "0.0.0.0/0"
+	anyIPv6CidrBlock = "::/0"
 
-	securityGroupName := "sg-caph"
+	// legacySecurityGroupName is the single security group created for the clusters
+	// before the security groups were created per role.
+	legacySecurityGroupName = "sg-caph"
+)
 
-	// Check if the security group already exists
-	listSecurityGroupsRequest := &model.ListSecurityGroupsRequest{
-		VpcId: &s.scope.VPC().Id,
-	}
-	listSecurityGroupsResponse, err := s.vpcClient.ListSecurityGroups(listSecurityGroupsRequest)
+// defaultCNIIngressRules are the rules required by Calico, the CNI used by the cluster templates.
+var defaultCNIIngressRules = infrav1alpha1.CNIIngressRules{
+	{
+		Description:  "bgp (calico)",
+		Protocol:     infrav1alpha1.SecurityGroupProtocolTCP,
+		PortRangeMin: 179,
+		PortRangeMax: 179,
+	},
+	{
+		Description: "IP-in-IP (calico)",
+		Protocol:    infrav1alpha1.SecurityGroupProtocolIPinIP,
+	},
+	{
+		Description:  "VXLAN (calico)",
+		Protocol:     infrav1alpha1.SecurityGroupProtocolUDP,
+		PortRangeMin: 4789,
+		PortRangeMax: 4789,
+	},
+}
+
+// ReconcileSecurityGroups creates one security group per role and makes sure
+// each of them has the ingress rules required by its role.
+func (s *Service) ReconcileSecurityGroups(ctx context.Context) error {
+	klog.Info("Reconciling security groups")
+
+	existing, err := s.describeSecurityGroupsByName(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list security groups: %v", err)
+		return err
 	}
 
-	var securityGroupID string
-	for _, sg := range *listSecurityGroupsResponse.SecurityGroups {
-		if sg.Name == securityGroupName {
-			securityGroupID = sg.Id
+	securityGroups := s.scope.SecurityGroups()
+	if securityGroups == nil {
+		securityGroups = make(map[infrav1alpha1.SecurityGroupRole]infrav1alpha1.SecurityGroup)
+	}
+
+	// First iteration makes sure the security groups exist, so that the rules
+	// below can reference any of them by role.
+	for _, role := range s.roles {
+		securityGroupName := s.securityGroupName(role)
+		securityGroupID, ok := existing[securityGroupName]
+		if ok {
 			klog.Infof("Security group already exists: %s", securityGroupID)
-			break
+		} else {
+			if recorded := securityGroups[role].ID; recorded != "" {
+				klog.Warningf("Security group %s of role %s no longer exists, re-creating it", recorded, role)
+			}
+			securityGroupID, err = s.createSecurityGroup(ctx, securityGroupName)
+			if err != nil {
+				return err
+			}
 		}
+
+		sg := securityGroups[role]
+		sg.ID = securityGroupID
+		sg.Name = securityGroupName
+		securityGroups[role] = sg
 	}
-	// If the security group does not exist, create it
-	if securityGroupID == "" {
-		createSecurityGroupRequest := &model.CreateSecurityGroupRequest{
-			Body: &model.CreateSecurityGroupRequestBody{
-				SecurityGroup: &model.CreateSecurityGroupOption{
-					VpcId: &s.scope.VPC().Id,
-					Name:  securityGroupName,
-				},
-			},
-		}
-		createSecurityGroupResponse, err := s.vpcClient.CreateSecurityGroup(createSecurityGroupRequest)
+	s.scope.SetSecurityGroups(securityGroups)
+
+	// Second iteration reconciles the ingress rules of each security group.
+	for _, role := range s.roles {
+		sg := securityGroups[role]
+
+		securityGroupRules, err := s.reconcileSecurityGroupRules(ctx, role, sg.ID)
 		if err != nil {
-			return fmt.Errorf("failed to create security group: %v", err)
+			return err
 		}
-		securityGroupID = createSecurityGroupResponse.SecurityGroup.Id
-		klog.Infof("Created security group: %s", securityGroupID)
+
+		sg.SecurityGroupRules = securityGroupRules
+		securityGroups[role] = sg
 	}
 
-	// Define ingress rules
-	ingressRules := []model.NeutronCreateSecurityGroupRuleOption{
-		{
-			SecurityGroupId: securityGroupID,
-			Direction:       model.GetNeutronCreateSecurityGroupRuleOptionDirectionEnum().INGRESS,
-			PortRangeMax:    int32Ptr(22),
-			PortRangeMin:    int32Ptr(22),
-			Protocol:        strPtr("tcp"),
-			RemoteIpPrefix:  strPtr("0.0.0.0/0"),
-		},
-		{
-			SecurityGroupId: securityGroupID,
-			Direction:       model.GetNeutronCreateSecurityGroupRuleOptionDirectionEnum().INGRESS,
-			PortRangeMax:    int32Ptr(6443),
-			PortRangeMin:    int32Ptr(6443),
-			Protocol:        strPtr("tcp"),
-			RemoteIpPrefix:  strPtr("0.0.0.0/0"),
-		},
+	s.scope.SetSecurityGroups(securityGroups)
+
+	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.ClusterSecurityGroupsReadyCondition)
+	if err := s.scope.PatchObject(); err != nil {
+		return fmt.Errorf("failed to patch HCCluster: %v", err)
+	}
+	return nil
+}
+
+// reconcileSecurityGroupRules computes the difference between the desired and the actual ingress
+// rules of the security group. Missing rules are created and rules which are not desired, including
+// the ones added out of band, are revoked. It returns the resulting ingress rules of the group.
+func (s *Service) reconcileSecurityGroupRules(ctx context.Context, role infrav1alpha1.SecurityGroupRole, securityGroupID string) ([]infrav1alpha1.SecurityGroupRule, error) {
+	ingressRules, err := s.getSecurityGroupIngressRules(role)
+	if err != nil {
+		return nil, err
+	}
+	desiredRules, err := s.expandIngressRules(ingressRules)
+	if err != nil {
+		return nil, err
+	}
+	desiredIPv4, desiredIPv6 := s.ingressRulesByEthertype(desiredRules)
+
+	actual, err := s.listIngressRules(ctx, securityGroupID)
+	if err != nil {
+		return nil, err
 	}
 
+	ethertypes := model.GetNeutronCreateSecurityGroupRuleOptionEthertypeEnum()
 	securityGroupRules := []infrav1alpha1.SecurityGroupRule{}
-	// Check and create ingress rules
-	for _, rule := range ingressRules {
-		if !s.securityGroupRuleExists(securityGroupID, rule) {
+	for _, family := range []struct {
+		ethertype model.NeutronCreateSecurityGroupRuleOptionEthertype
+		desired   infrav1alpha1.IngressRules
+	}{
+		{ethertype: ethertypes.I_PV4, desired: desiredIPv4},
+		{ethertype: ethertypes.I_PV6, desired: desiredIPv6},
+	} {
+		var actualRules []model.SecurityGroupRule
+		var actualIngressRules infrav1alpha1.IngressRules
+		for _, rule := range actual {
+			if rule.Ethertype == family.ethertype.Value() {
+				actualRules = append(actualRules, rule)
+				actualIngressRules = append(actualIngressRules, ingressRuleFromSDK(rule))
+			}
+		}
+
+		toAuthorize, toRevoke := ingressRulesChanges(family.desired, actualIngressRules)
+
+		// Revoke the rules which are no longer desired, e.g. after the allowed CIDRs
+		// changed or a rule was added to the security group out of band.
+		for i, existingRule := range actualRules {
+			// The rules of the lb security group are injected by the cloud provider.
+			if !slices.Contains(toRevoke, i) || role == infrav1alpha1.SecurityGroupLB {
+				securityGroupRules = append(securityGroupRules, sdkToSecurityGroupRule(existingRule))
+				continue
+			}
+			if err := s.deleteSecurityGroupRule(ctx, existingRule.Id); err != nil {
+				return nil, err
+			}
+			klog.Infof("Revoked security group rule: %s", existingRule.Id)
+		}
+
+		for _, ingressRule := range toAuthorize {
+			rule := s.ingressRuleToSDK(securityGroupID, family.ethertype, ingressRule)
 			createSecurityGroupRuleRequest := &model.NeutronCreateSecurityGroupRuleRequest{
 				Body: &model.NeutronCreateSecurityGroupRuleRequestBody{
 					SecurityGroupRule: &rule,
 				},
 			}
-			ruleRep, err := s.vpcClient.NeutronCreateSecurityGroupRule(createSecurityGroupRuleRequest)
+			ruleRep, err := s.vpcClient.NeutronCreateSecurityGroupRule(ctx, createSecurityGroupRuleRequest)
 			if err != nil {
-				return fmt.Errorf("failed to create security group rule: %v", err)
+				s.scope.Warningf(err, "FailedAuthorizeSecurityGroupIngressRules", "Failed to create rule %q in security group %s", ptr.Deref(rule.Description, ""), rule.SecurityGroupId)
+				return nil, fmt.Errorf("failed to create security group rule: %v", err)
 			}
+			s.scope.Eventf("SuccessfulAuthorizeSecurityGroupIngressRules", "Created rule %s in security group %s", ruleRep.SecurityGroupRule.Id, ruleRep.SecurityGroupRule.SecurityGroupId)
 
 			securityGroupRules = append(securityGroupRules, infrav1alpha1.SecurityGroupRule{
-				Id:             ruleRep.SecurityGroupRule.Id,
-				Direction:      ruleRep.SecurityGroupRule.Direction.Value(),
-				Ethertype:      ruleRep.SecurityGroupRule.Ethertype,
-				PortRangeMin:   ruleRep.SecurityGroupRule.PortRangeMin,
-				PortRangeMax:   ruleRep.SecurityGroupRule.PortRangeMax,
-				Protocol:       ruleRep.SecurityGroupRule.Protocol,
-				RemoteIpPrefix: ruleRep.SecurityGroupRule.RemoteIpPrefix,
+				Id:              ruleRep.SecurityGroupRule.Id,
+				Description:     ruleRep.SecurityGroupRule.Description,
+				SecurityGroupId: ruleRep.SecurityGroupRule.SecurityGroupId,
+				Direction:       ruleRep.SecurityGroupRule.Direction.Value(),
+				Ethertype:       ruleRep.SecurityGroupRule.Ethertype,
+				PortRangeMin:    ruleRep.SecurityGroupRule.PortRangeMin,
+				PortRangeMax:    ruleRep.SecurityGroupRule.PortRangeMax,
+				Protocol:        ruleRep.SecurityGroupRule.Protocol,
+				RemoteIpPrefix:  ruleRep.SecurityGroupRule.RemoteIpPrefix,
+				RemoteGroupId:   ruleRep.SecurityGroupRule.RemoteGroupId,
 			})
 			klog.Infof("Created security group rule: %+v", rule)
-		} else {
-			klog.Infof("Security group rule already exists: %+v", rule)
 		}
 	}
 
-	securityGroups := s.scope.SecurityGroups()
-	if securityGroups == nil {
-		securityGroups = make(map[infrav1alpha1.SecurityGroupRole]infrav1alpha1.SecurityGroup)
-		s.scope.SetSecurityGroups(securityGroups)
-	}
+	return securityGroupRules, nil
+}
 
-	for _, role := range s.roles {
-		securityGroups[role] = infrav1alpha1.SecurityGroup{
-			ID:                 securityGroupID,
-			Name:               securityGroupName,
-			SecurityGroupRules: securityGroupRules,
+// ingressRulesChanges compares the desired ingress rules with the actual rules of a security group.
+// It returns the rules to create and the indexes of the actual rules to revoke.
+func ingressRulesChanges(desired, actual infrav1alpha1.IngressRules) (toAuthorize infrav1alpha1.IngressRules, toRevoke []int) {
+	toAuthorize = desired.Difference(actual)
+	for i := range actual {
+		if len(actual[i:i+1].Difference(desired)) > 0 {
+			toRevoke = append(toRevoke, i)
 		}
 	}
+	return toAuthorize, toRevoke
+}
 
-	s.scope.SetSecurityGroups(securityGroups)
+// ingressRulesByEthertype splits the expanded ingress rules into the rules of the IPv4 and the IPv6 ethertypes.
+// The rules with a source security group apply to both ethertypes if IPv6 is enabled on the VPC.
+func (s *Service) ingressRulesByEthertype(rules infrav1alpha1.IngressRules) (ipv4, ipv6 infrav1alpha1.IngressRules) {
+	for _, rule := range rules {
+		switch {
+		case len(rule.CidrBlocks) > 0:
+			ipv4 = append(ipv4, rule)
+		case len(rule.IPv6CidrBlocks) > 0:
+			ipv6 = append(ipv6, rule)
+		case len(rule.SourceSecurityGroupIDs) > 0:
+			ipv4 = append(ipv4, rule)
+			if s.scope.VPC().IPv6Enabled {
+				ipv6 = append(ipv6, rule)
+			}
+		}
+	}
+	return ipv4, ipv6
+}
 
-	conditions.MarkTrue(s.scope.InfraCluster(), infrav1alpha1.ClusterSecurityGroupsReadyCondition)
-	if err := s.scope.PatchObject(); err != nil {
-		return fmt.Errorf("failed to patch HCCluster: %v", err)
+// ingressRuleFromSDK converts a rule of a security group into an IngressRule with a single source,
+// so that it can be compared with the expanded desired rules.
+func ingressRuleFromSDK(rule model.SecurityGroupRule) infrav1alpha1.IngressRule {
+	ingressRule := infrav1alpha1.IngressRule{
+		Description:  rule.Description,
+		Protocol:     infrav1alpha1.SecurityGroupProtocol(rule.Protocol),
+		PortRangeMin: int64(rule.PortRangeMin),
+		PortRangeMax: int64(rule.PortRangeMax),
 	}
-	return nil
+	if rule.Protocol == "" {
+		ingressRule.Protocol = infrav1alpha1.SecurityGroupProtocolAll
+	}
+	switch {
+	case rule.RemoteGroupId != "":
+		ingressRule.SourceSecurityGroupIDs = []string{rule.RemoteGroupId}
+	case rule.RemoteIpPrefix != "" && rule.Ethertype == model.GetNeutronCreateSecurityGroupRuleOptionEthertypeEnum().I_PV6.Value():
+		ingressRule.IPv6CidrBlocks = []string{rule.RemoteIpPrefix}
+	case rule.RemoteIpPrefix != "":
+		ingressRule.CidrBlocks = []string{rule.RemoteIpPrefix}
+	}
+	return ingressRule
 }
 
-func (s *Service) securityGroupRuleExists(
-	securityGroupID string, rule model.NeutronCreateSecurityGroupRuleOption,
-) bool {
+// listIngressRules returns the ingress rules of the security group. Egress rules are not managed.
+func (s *Service) listIngressRules(ctx context.Context, securityGroupID string) ([]model.SecurityGroupRule, error) {
 	listSecurityGroupRulesRequest := &model.ListSecurityGroupRulesRequest{
 		SecurityGroupId: &securityGroupID,
 	}
-	listSecurityGroupRulesResponse, err := s.vpcClient.ListSecurityGroupRules(listSecurityGroupRulesRequest)
+	listSecurityGroupRulesResponse, err := s.vpcClient.ListSecurityGroupRules(ctx, listSecurityGroupRulesRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security group rules: %v", err)
+	}
+
+	rules := []model.SecurityGroupRule{}
+	for _, rule := range *listSecurityGroupRulesResponse.SecurityGroupRules {
+		if rule.Direction == model.GetNeutronCreateSecurityGroupRuleOptionDirectionEnum().INGRESS.Value() {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// securityGroupName returns the name of the security group owned by the cluster for the given role.
+func (s *Service) securityGroupName(role infrav1alpha1.SecurityGroupRole) string {
+	return fmt.Sprintf("%s-%s", s.scope.ClusterName(), role)
+}
+
+// describeSecurityGroupsByName returns the security groups of the cluster VPC, keyed by name.
+func (s *Service) describeSecurityGroupsByName(ctx context.Context) (map[string]string, error) {
+	listSecurityGroupsRequest := &model.ListSecurityGroupsRequest{
+		VpcId: &s.scope.VPC().Id,
+	}
+	listSecurityGroupsResponse, err := s.vpcClient.ListSecurityGroups(ctx, listSecurityGroupsRequest)
 	if err != nil {
-		klog.Errorf("Failed to list security group rules: %v", err)
-		return false
+		return nil, fmt.Errorf("failed to list security groups: %v", err)
+	}
+
+	securityGroups := map[string]string{}
+	for _, sg := range *listSecurityGroupsResponse.SecurityGroups {
+		securityGroups[sg.Name] = sg.Id
+	}
+	return securityGroups, nil
+}
+
+func (s *Service) createSecurityGroup(ctx context.Context, name string) (string, error) {
+	createSecurityGroupRequest := &model.CreateSecurityGroupRequest{
+		Body: &model.CreateSecurityGroupRequestBody{
+			SecurityGroup: &model.CreateSecurityGroupOption{
+				VpcId: &s.scope.VPC().Id,
+				Name:  name,
+			},
+		},
+	}
+	createSecurityGroupResponse, err := s.vpcClient.CreateSecurityGroup(ctx, createSecurityGroupRequest)
+	if err != nil {
+		s.scope.Warningf(err, "FailedCreateSecurityGroup", "Failed to create security group %s", name)
+		return "", fmt.Errorf("failed to create security group %s: %v", name, err)
+	}
+	klog.Infof("Created security group: %s", createSecurityGroupResponse.SecurityGroup.Id)
+	s.scope.Eventf("SuccessfulCreateSecurityGroup", "Created security group %s (%s)", name, createSecurityGroupResponse.SecurityGroup.Id)
+	return createSecurityGroupResponse.SecurityGroup.Id, nil
+}
+
+// getSecurityGroupIngressRules returns the ingress rules required by the given role,
+// including the additional rules defined by the user for that role.
+func (s *Service) getSecurityGroupIngressRules(role infrav1alpha1.SecurityGroupRole) (infrav1alpha1.IngressRules, error) {
+	anyIPv4, anyIPv6, _ := s.cidrBlocksByFamily(nil)
+
+	apiServerIPv4, apiServerIPv6, err := s.cidrBlocksByFamily(s.scope.APIServerAllowedCIDRs())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid API server allowed CIDRs")
+	}
+	apiServerRule := func(port int32) infrav1alpha1.IngressRule {
+		return infrav1alpha1.IngressRule{
+			Description:    "Kubernetes API",
+			Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+			PortRangeMin:   int64(port),
+			PortRangeMax:   int64(port),
+			CidrBlocks:     apiServerIPv4,
+			IPv6CidrBlocks: apiServerIPv6,
+		}
+	}
+	controlPlaneAPIServerRules := infrav1alpha1.IngressRules{apiServerRule(s.scope.APIServerBackendPort())}
+	lbAPIServerRules := infrav1alpha1.IngressRules{apiServerRule(s.scope.APIServerPort())}
+	if secondary := s.scope.SecondaryControlPlaneLoadBalancer(); secondary != nil {
+		// the secondary load balancer may use different ports than the primary one
+		port, backendPort := secondary.Port, secondary.BackendPort
+		if port == 0 {
+			port = infrav1alpha1.DefaultAPIServerPort
+		}
+		if backendPort == 0 {
+			backendPort = infrav1alpha1.DefaultAPIServerPort
+		}
+		if backendPort != s.scope.APIServerBackendPort() {
+			controlPlaneAPIServerRules = append(controlPlaneAPIServerRules, apiServerRule(backendPort))
+		}
+		if port != s.scope.APIServerPort() {
+			lbAPIServerRules = append(lbAPIServerRules, apiServerRule(port))
+		}
+	}
+	additionalListenerRules := infrav1alpha1.IngressRules{}
+	for _, listener := range s.scope.ControlPlaneLoadBalancer().AdditionalListeners {
+		additionalListenerRules = append(additionalListenerRules, infrav1alpha1.IngressRule{
+			Description:    fmt.Sprintf("Load balancer listener %d", listener.Port),
+			Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+			PortRangeMin:   int64(listener.Port),
+			PortRangeMax:   int64(listener.Port),
+			CidrBlocks:     anyIPv4,
+			IPv6CidrBlocks: anyIPv6,
+		})
+	}
+	kubeletRule := infrav1alpha1.IngressRule{
+		Description:  "Kubelet API",
+		Protocol:     infrav1alpha1.SecurityGroupProtocolTCP,
+		PortRangeMin: 10250,
+		PortRangeMax: 10250,
+		SourceSecurityGroupRoles: []infrav1alpha1.SecurityGroupRole{
+			infrav1alpha1.SecurityGroupControlPlane,
+			infrav1alpha1.SecurityGroupNode,
+		},
+	}
+
+	sshRules := infrav1alpha1.IngressRules{}
+	if ssh := s.scope.SSH(); ssh == nil || !ssh.Disabled {
+		var allowedCIDRs []string
+		if ssh != nil {
+			allowedCIDRs = ssh.AllowedCIDRs
+		}
+		sshIPv4, sshIPv6, err := s.cidrBlocksByFamily(allowedCIDRs)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid SSH allowed CIDRs")
+		}
+		sshRules = append(sshRules, infrav1alpha1.IngressRule{
+			Description:    "SSH",
+			Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+			PortRangeMin:   22,
+			PortRangeMax:   22,
+			CidrBlocks:     sshIPv4,
+			IPv6CidrBlocks: sshIPv6,
+		})
 	}
 
-	for _, existingRule := range *listSecurityGroupRulesResponse.SecurityGroupRules {
-		if existingRule.Direction == rule.Direction.Value() &&
-			existingRule.PortRangeMin == *rule.PortRangeMin &&
-			existingRule.PortRangeMax == *rule.PortRangeMax &&
-			existingRule.Protocol == *rule.Protocol &&
-			existingRule.RemoteIpPrefix == *rule.RemoteIpPrefix {
-			return true
+	// HuaweiCloud adds a rule allowing all traffic within a new security group,
+	// it is kept so that the members of a group can reach each other.
+	rules := infrav1alpha1.IngressRules{
+		{
+			Protocol:                 infrav1alpha1.SecurityGroupProtocolAll,
+			SourceSecurityGroupRoles: []infrav1alpha1.SecurityGroupRole{role},
+		},
+	}
+	switch role {
+	case infrav1alpha1.SecurityGroupControlPlane:
+		rules = append(rules, sshRules...)
+		rules = append(rules, controlPlaneAPIServerRules...)
+		rules = append(rules,
+			infrav1alpha1.IngressRule{
+				Description:  "etcd",
+				Protocol:     infrav1alpha1.SecurityGroupProtocolTCP,
+				PortRangeMin: 2379,
+				PortRangeMax: 2380,
+				SourceSecurityGroupRoles: []infrav1alpha1.SecurityGroupRole{
+					infrav1alpha1.SecurityGroupControlPlane,
+				},
+			},
+			kubeletRule,
+		)
+		rules = append(rules, additionalListenerRules...)
+		rules = append(rules, s.getCNIIngressRules()...)
+	case infrav1alpha1.SecurityGroupNode:
+		rules = append(rules, sshRules...)
+		rules = append(rules,
+			infrav1alpha1.IngressRule{
+				Description:    "Node Port Services",
+				Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+				PortRangeMin:   30000,
+				PortRangeMax:   32767,
+				CidrBlocks:     anyIPv4,
+				IPv6CidrBlocks: anyIPv6,
+			},
+			kubeletRule,
+		)
+		rules = append(rules, s.getCNIIngressRules()...)
+	case infrav1alpha1.SecurityGroupAPIServerLB:
+		rules = append(rules, lbAPIServerRules...)
+		rules = append(rules, additionalListenerRules...)
+	case infrav1alpha1.SecurityGroupLB:
+		// The lb security group is a container for the cloud provider to inject its load balancer rules.
+	default:
+		return nil, errors.Errorf("Cannot determine ingress rules for unknown security group role %q", role)
+	}
+
+	return append(rules, s.scope.AdditionalIngressRules(role)...), nil
+}
+
+// cidrBlocksByFamily splits the CIDR blocks into IPv4 and IPv6 blocks. When no block is given,
+// any IPv4 address is allowed, as well as any IPv6 address if IPv6 is enabled on the VPC.
+func (s *Service) cidrBlocksByFamily(cidrs []string) (ipv4 []string, ipv6 []string, err error) {
+	if len(cidrs) == 0 {
+		ipv4 = []string{anyIPv4CidrBlock}
+		if s.scope.VPC().IPv6Enabled {
+			ipv6 = []string{anyIPv6CidrBlock}
+		}
+		return ipv4, ipv6, nil
+	}
+
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ip.To4() != nil {
+			ipv4 = append(ipv4, cidr)
+		} else {
+			ipv6 = append(ipv6, cidr)
 		}
 	}
-	return false
+	return ipv4, ipv6, nil
 }
 
-func int32Ptr(i int32) *int32 {
-	return &i
+// getCNIIngressRules returns the CNI rules, allowed between the control plane and node security groups.
+func (s *Service) getCNIIngressRules() infrav1alpha1.IngressRules {
+	cniRules := s.scope.CNIIngressRules()
+	if len(cniRules) == 0 {
+		cniRules = defaultCNIIngressRules
+	}
+
+	rules := make(infrav1alpha1.IngressRules, 0, len(cniRules))
+	for _, r := range cniRules {
+		rules = append(rules, infrav1alpha1.IngressRule{
+			Description:  r.Description,
+			Protocol:     r.Protocol,
+			PortRangeMin: r.PortRangeMin,
+			PortRangeMax: r.PortRangeMax,
+			SourceSecurityGroupRoles: []infrav1alpha1.SecurityGroupRole{
+				infrav1alpha1.SecurityGroupControlPlane,
+				infrav1alpha1.SecurityGroupNode,
+			},
+		})
+	}
+	return rules
 }
 
-func strPtr(s string) *string {
-	return &s
+// expandIngressRules splits the rules into rules with a single source each, so they can be
+// compared with the rules of a security group. SourceSecurityGroupRoles are resolved to the
+// IDs of the cluster security groups and NatGatewaysIPsSource to the /32 CIDRs of the current
+// NAT gateways IPs.
+func (s *Service) expandIngressRules(rules infrav1alpha1.IngressRules) (infrav1alpha1.IngressRules, error) {
+	newRule := func(rule infrav1alpha1.IngressRule) infrav1alpha1.IngressRule {
+		r := infrav1alpha1.IngressRule{
+			Description: rule.Description,
+			Protocol:    rule.Protocol,
+		}
+		// the ports are only set on the TCP and UDP rules of the security group
+		switch rule.Protocol {
+		case infrav1alpha1.SecurityGroupProtocolTCP, infrav1alpha1.SecurityGroupProtocolUDP:
+			r.PortRangeMin, r.PortRangeMax = rule.PortRangeMin, rule.PortRangeMax
+		}
+		return r
+	}
+
+	expanded := infrav1alpha1.IngressRules{}
+	for _, rule := range rules {
+		for _, cidr := range rule.CidrBlocks {
+			r := newRule(rule)
+			r.CidrBlocks = []string{cidr}
+			expanded = append(expanded, r)
+		}
+		for _, cidr := range rule.IPv6CidrBlocks {
+			r := newRule(rule)
+			r.IPv6CidrBlocks = []string{cidr}
+			expanded = append(expanded, r)
+		}
+		if rule.NatGatewaysIPsSource {
+			natGatewaysIPs := s.scope.Network().NatGatewaysIPs
+			if len(natGatewaysIPs) == 0 {
+				klog.Infof("No NAT gateways IPs available, skipping source of ingress rule %q", rule.Description)
+			}
+			for _, ip := range natGatewaysIPs {
+				r := newRule(rule)
+				r.CidrBlocks = []string{fmt.Sprintf("%s/32", ip)}
+				expanded = append(expanded, r)
+			}
+		}
+
+		groupIDs := append([]string{}, rule.SourceSecurityGroupIDs...)
+		for _, role := range rule.SourceSecurityGroupRoles {
+			sg, ok := s.scope.SecurityGroups()[role]
+			if !ok || sg.ID == "" {
+				return nil, errors.Errorf("%s security group not available", role)
+			}
+			groupIDs = append(groupIDs, sg.ID)
+		}
+		for _, groupID := range groupIDs {
+			r := newRule(rule)
+			r.SourceSecurityGroupIDs = []string{groupID}
+			expanded = append(expanded, r)
+		}
+	}
+	return expanded, nil
+}
+
+// ingressRuleToSDK converts an IngressRule with a single source into a security group rule of the ethertype.
+func (s *Service) ingressRuleToSDK(securityGroupID string, ethertype model.NeutronCreateSecurityGroupRuleOptionEthertype,
+	rule infrav1alpha1.IngressRule) model.NeutronCreateSecurityGroupRuleOption {
+	option := model.NeutronCreateSecurityGroupRuleOption{
+		SecurityGroupId: securityGroupID,
+		Direction:       model.GetNeutronCreateSecurityGroupRuleOptionDirectionEnum().INGRESS,
+		Ethertype:       ptr.To(ethertype),
+	}
+	if rule.Description != "" {
+		option.Description = ptr.To(rule.Description)
+	}
+	if rule.Protocol != infrav1alpha1.SecurityGroupProtocolAll {
+		option.Protocol = ptr.To(string(rule.Protocol))
+	}
+	switch rule.Protocol {
+	case infrav1alpha1.SecurityGroupProtocolTCP, infrav1alpha1.SecurityGroupProtocolUDP:
+		option.PortRangeMin = ptr.To(int32(rule.PortRangeMin))
+		option.PortRangeMax = ptr.To(int32(rule.PortRangeMax))
+	}
+
+	switch {
+	case len(rule.CidrBlocks) > 0:
+		option.RemoteIpPrefix = ptr.To(rule.CidrBlocks[0])
+	case len(rule.IPv6CidrBlocks) > 0:
+		option.RemoteIpPrefix = ptr.To(rule.IPv6CidrBlocks[0])
+	case len(rule.SourceSecurityGroupIDs) > 0:
+		option.RemoteGroupId = ptr.To(rule.SourceSecurityGroupIDs[0])
+	}
+	return option
+}
+
+func sdkToSecurityGroupRule(rule model.SecurityGroupRule) infrav1alpha1.SecurityGroupRule {
+	return infrav1alpha1.SecurityGroupRule{
+		Id:                   rule.Id,
+		Description:          rule.Description,
+		SecurityGroupId:      rule.SecurityGroupId,
+		Direction:            rule.Direction,
+		Ethertype:            rule.Ethertype,
+		Protocol:             rule.Protocol,
+		PortRangeMin:         rule.PortRangeMin,
+		PortRangeMax:         rule.PortRangeMax,
+		RemoteIpPrefix:       rule.RemoteIpPrefix,
+		RemoteGroupId:        rule.RemoteGroupId,
+		RemoteAddressGroupId: rule.RemoteAddressGroupId,
+	}
 }
 
-func (s *Service) DeleteSecurityGroups() error {
+func (s *Service) DeleteSecurityGroups(ctx context.Context) error {
 	klog.Info("Deleting security groups")
 	if s.scope.VPC().Id == "" {
-		klog.Info("Skipping security group deletion, vpc-id is nil", "vpc-id", s.scope.VPC().Id)
+		klog.InfoS("Skipping security group deletion, vpc-id is nil", "vpc-id", s.scope.VPC().Id)
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
 			infrav1alpha1.ClusterSecurityGroupsReadyCondition,
@@ -177,12 +615,8 @@ func (s *Service) DeleteSecurityGroups() error {
 		return err
 	}
 
-	// Retrieve the security group by name
-	securityGroupName := "sg-caph"
-	listSecurityGroupsRequest := &model.NeutronListSecurityGroupsRequest{
-		Name: &securityGroupName,
-	}
-	listSecurityGroupsResponse, err := s.vpcClient.NeutronListSecurityGroups(listSecurityGroupsRequest)
+	// Retrieve the security groups by name
+	existing, err := s.describeSecurityGroupsByName(ctx)
 	if err != nil {
 		conditions.MarkFalse(
 			s.scope.InfraCluster(),
@@ -190,71 +624,132 @@ func (s *Service) DeleteSecurityGroups() error {
 			"DeletingFailed",
 			clusterv1.ConditionSeverityWarning,
 			"failed to list security groups")
-		return fmt.Errorf("failed to list security groups: %v", err)
+		return err
 	}
-	securityGroups := *listSecurityGroupsResponse.SecurityGroups
-	if len(securityGroups) == 0 {
-		klog.Infof("No security group found with name: %s", securityGroupName)
-		conditions.MarkFalse(
-			s.scope.InfraCluster(),
-			infrav1alpha1.ClusterSecurityGroupsReadyCondition,
-			clusterv1.DeletedReason,
-			clusterv1.ConditionSeverityInfo,
-			"")
-		return nil
+	// The security groups are found by name, and by the IDs recorded in the status in case they were renamed.
+	existingIDs := make(map[string]bool, len(existing))
+	for _, id := range existing {
+		existingIDs[id] = true
 	}
-	securityGroupID := securityGroups[0].Id
-	klog.Infof("Found security group: %s", securityGroupID)
-	// Delete all security group rules
-	listSecurityGroupRulesRequest := &model.NeutronListSecurityGroupRulesRequest{
-		SecurityGroupId: &securityGroupID,
+	securityGroupIDs := make([]string, 0, len(s.roles))
+	for _, role := range s.roles {
+		if securityGroupID, ok := existing[s.securityGroupName(role)]; ok && !slices.Contains(securityGroupIDs, securityGroupID) {
+			klog.Infof("Found security group: %s", securityGroupID)
+			securityGroupIDs = append(securityGroupIDs, securityGroupID)
+		}
 	}
-	listSecurityGroupRulesResponse, err := s.vpcClient.NeutronListSecurityGroupRules(listSecurityGroupRulesRequest)
-	if err != nil {
-		conditions.MarkFalse(
-			s.scope.InfraCluster(),
-			infrav1alpha1.ClusterSecurityGroupsReadyCondition,
-			"DeletingFailed",
-			clusterv1.ConditionSeverityWarning,
-			"failed to list security group rules")
-		return fmt.Errorf("failed to list security group rules: %v", err)
+	for _, sg := range s.scope.SecurityGroups() {
+		if existingIDs[sg.ID] && !slices.Contains(securityGroupIDs, sg.ID) {
+			klog.Infof("Found security group recorded in status: %s", sg.ID)
+			securityGroupIDs = append(securityGroupIDs, sg.ID)
+		}
 	}
-	for _, rule := range *listSecurityGroupRulesResponse.SecurityGroupRules {
-		deleteSecurityGroupRuleRequest := &model.NeutronDeleteSecurityGroupRuleRequest{
-			SecurityGroupRuleId: rule.Id,
+
+	// Delete all security group rules first, a rule referencing another
+	// cluster security group would otherwise prevent its deletion.
+	for _, securityGroupID := range securityGroupIDs {
+		if err := s.deleteSecurityGroupRules(ctx, securityGroupID); err != nil {
+			conditions.MarkFalse(
+				s.scope.InfraCluster(),
+				infrav1alpha1.ClusterSecurityGroupsReadyCondition,
+				"DeletingFailed",
+				clusterv1.ConditionSeverityWarning,
+				"failed to delete security group rules")
+			return err
+		}
+	}
+
+	// Delete the security groups
+	for _, securityGroupID := range securityGroupIDs {
+		deleteSecurityGroupRequest := &model.NeutronDeleteSecurityGroupRequest{
+			SecurityGroupId: securityGroupID,
 		}
-		_, err := s.vpcClient.NeutronDeleteSecurityGroupRule(deleteSecurityGroupRuleRequest)
+		_, err = s.vpcClient.NeutronDeleteSecurityGroup(ctx, deleteSecurityGroupRequest)
 		if err != nil {
 			conditions.MarkFalse(
 				s.scope.InfraCluster(),
 				infrav1alpha1.ClusterSecurityGroupsReadyCondition,
 				"DeletingFailed",
 				clusterv1.ConditionSeverityWarning,
-				"failed to delete security group rule")
-			return fmt.Errorf("failed to delete security group rule: %v", err)
+				"failed to delete security group")
+			s.scope.Warningf(err, "FailedDeleteSecurityGroup", "Failed to delete security group %s", securityGroupID)
+			return errors.Wrapf(err, "failed to delete security group %s", securityGroupID)
 		}
-		klog.Infof("Deleted security group rule: %s", rule.Id)
-	}
-	// Delete the security group
-	deleteSecurityGroupRequest := &model.NeutronDeleteSecurityGroupRequest{
-		SecurityGroupId: securityGroupID,
+		klog.Infof("Deleted security group: %s", securityGroupID)
+		s.scope.Eventf("SuccessfulDeleteSecurityGroup", "Deleted security group %s", securityGroupID)
 	}
-	_, err = s.vpcClient.NeutronDeleteSecurityGroup(deleteSecurityGroupRequest)
-	if err != nil {
-		conditions.MarkFalse(
-			s.scope.InfraCluster(),
-			infrav1alpha1.ClusterSecurityGroupsReadyCondition,
-			"DeletingFailed",
-			clusterv1.ConditionSeverityWarning,
-			"failed to delete security group")
-		return fmt.Errorf("failed to delete security group: %v", err)
+
+	if legacyID, ok := existing[legacySecurityGroupName]; ok {
+		if err := s.deleteLegacySecurityGroup(ctx, legacyID); err != nil {
+			conditions.MarkFalse(
+				s.scope.InfraCluster(),
+				infrav1alpha1.ClusterSecurityGroupsReadyCondition,
+				"DeletingFailed",
+				clusterv1.ConditionSeverityWarning,
+				"failed to delete security group")
+			return err
+		}
 	}
+
 	conditions.MarkFalse(
 		s.scope.InfraCluster(),
 		infrav1alpha1.ClusterSecurityGroupsReadyCondition,
 		clusterv1.DeletedReason,
 		clusterv1.ConditionSeverityInfo,
 		"")
-	klog.Infof("Deleted security group: %s", securityGroupID)
+	return nil
+}
+
+// deleteLegacySecurityGroup deletes the security group shared by the clusters created before the security groups
+// were created per role. The group is kept while it is still used, e.g. by the machines of another cluster of the VPC.
+func (s *Service) deleteLegacySecurityGroup(ctx context.Context, securityGroupID string) error {
+	_, err := s.vpcClient.NeutronDeleteSecurityGroup(ctx, &model.NeutronDeleteSecurityGroupRequest{
+		SecurityGroupId: securityGroupID,
+	})
+	switch {
+	case err == nil:
+		klog.Infof("Deleted legacy security group %s: %s", legacySecurityGroupName, securityGroupID)
+		s.scope.Eventf("SuccessfulDeleteSecurityGroup", "Deleted legacy security group %s (%s)", legacySecurityGroupName, securityGroupID)
+	case ecserrors.StatusCode(err) == http.StatusNotFound:
+	case ecserrors.IsDependencyInUse(err):
+		klog.Infof("Keeping legacy security group %s (%s), it is still in use", legacySecurityGroupName, securityGroupID)
+	default:
+		s.scope.Warningf(err, "FailedDeleteSecurityGroup", "Failed to delete legacy security group %s (%s)", legacySecurityGroupName, securityGroupID)
+		return errors.Wrapf(err, "failed to delete legacy security group %s", securityGroupID)
+	}
+	return nil
+}
+
+func (s *Service) deleteSecurityGroupRule(ctx context.Context, securityGroupRuleID string) error {
+	deleteSecurityGroupRuleRequest := &model.NeutronDeleteSecurityGroupRuleRequest{
+		SecurityGroupRuleId: securityGroupRuleID,
+	}
+	_, err := s.vpcClient.NeutronDeleteSecurityGroupRule(ctx, deleteSecurityGroupRuleRequest)
+	if ecserrors.StatusCode(err) == http.StatusNotFound {
+		klog.Infof("Security group rule %s already deleted", securityGroupRuleID)
+		return nil
+	}
+	if err != nil {
+		s.scope.Warningf(err, "FailedRevokeSecurityGroupIngressRules", "Failed to delete security group rule %s", securityGroupRuleID)
+		return fmt.Errorf("failed to delete security group rule: %v", err)
+	}
+	s.scope.Eventf("SuccessfulRevokeSecurityGroupIngressRules", "Deleted security group rule %s", securityGroupRuleID)
+	return nil
+}
+
+func (s *Service) deleteSecurityGroupRules(ctx context.Context, securityGroupID string) error {
+	listSecurityGroupRulesRequest := &model.NeutronListSecurityGroupRulesRequest{
+		SecurityGroupId: &securityGroupID,
+	}
+	listSecurityGroupRulesResponse, err := s.vpcClient.NeutronListSecurityGroupRules(ctx, listSecurityGroupRulesRequest)
+	if err != nil {
+		return fmt.Errorf("failed to list security group rules: %v", err)
+	}
+	for _, rule := range *listSecurityGroupRulesResponse.SecurityGroupRules {
+		if err := s.deleteSecurityGroupRule(ctx, rule.Id); err != nil {
+			return err
+		}
+		klog.Infof("Deleted security group rule: %s", rule.Id)
+	}
 	return nil
 }
@@ -0,0 +1,246 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package securitygroup
+
+import (
+	"testing"
+
+	"github.com/huaweicloud/huaweicloud-sdk-go-v3/services/vpc/v2/model"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1alpha1 "github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/api/v1alpha1"
+	"github.com/HuaweiCloudDeveloper/cluster-api-provider-huawei/pkg/scope"
+)
+
+// newTestService returns a service of a cluster whose security groups already exist.
+func newTestService(spec infrav1alpha1.HuaweiCloudClusterSpec) *Service {
+	hcCluster := &infrav1alpha1.HuaweiCloudCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       spec,
+	}
+	hcCluster.Status.Network.SecurityGroups = map[infrav1alpha1.SecurityGroupRole]infrav1alpha1.SecurityGroup{
+		infrav1alpha1.SecurityGroupAPIServerLB:  {ID: "sg-apiserver-lb"},
+		infrav1alpha1.SecurityGroupLB:           {ID: "sg-lb"},
+		infrav1alpha1.SecurityGroupControlPlane: {ID: "sg-controlplane"},
+		infrav1alpha1.SecurityGroupNode:         {ID: "sg-node"},
+	}
+	return &Service{
+		scope: &scope.ClusterScope{
+			Cluster:   &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}},
+			HCCluster: hcCluster,
+		},
+	}
+}
+
+// sshRule returns the expanded SSH ingress rule from the CIDR block.
+func sshRule(cidr string) infrav1alpha1.IngressRule {
+	return infrav1alpha1.IngressRule{
+		Description:  "SSH",
+		Protocol:     infrav1alpha1.SecurityGroupProtocolTCP,
+		PortRangeMin: 22,
+		PortRangeMax: 22,
+		CidrBlocks:   []string{cidr},
+	}
+}
+
+func TestIngressRulesChanges(t *testing.T) {
+	tests := []struct {
+		name            string
+		desired         infrav1alpha1.IngressRules
+		actual          infrav1alpha1.IngressRules
+		wantToAuthorize infrav1alpha1.IngressRules
+		wantToRevoke    []int
+	}{
+		{
+			name:    "up to date",
+			desired: infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+			actual:  infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+		},
+		{
+			name:            "missing rule is authorized",
+			desired:         infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+			actual:          nil,
+			wantToAuthorize: infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+		},
+		{
+			name:            "changed CIDR is replaced",
+			desired:         infrav1alpha1.IngressRules{sshRule("10.0.0.0/8")},
+			actual:          infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+			wantToAuthorize: infrav1alpha1.IngressRules{sshRule("10.0.0.0/8")},
+			wantToRevoke:    []int{0},
+		},
+		{
+			name:         "rule added out of band is revoked",
+			desired:      infrav1alpha1.IngressRules{sshRule("10.0.0.0/8")},
+			actual:       infrav1alpha1.IngressRules{sshRule("10.0.0.0/8"), sshRule("192.0.2.0/24")},
+			wantToRevoke: []int{1},
+		},
+		{
+			name:    "changed description is replaced",
+			desired: infrav1alpha1.IngressRules{sshRule("10.0.0.0/8")},
+			actual: infrav1alpha1.IngressRules{{
+				Description:  "ssh",
+				Protocol:     infrav1alpha1.SecurityGroupProtocolTCP,
+				PortRangeMin: 22,
+				PortRangeMax: 22,
+				CidrBlocks:   []string{"10.0.0.0/8"},
+			}},
+			wantToAuthorize: infrav1alpha1.IngressRules{sshRule("10.0.0.0/8")},
+			wantToRevoke:    []int{0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			toAuthorize, toRevoke := ingressRulesChanges(tt.desired, tt.actual)
+			g.Expect(toAuthorize).To(Equal(tt.wantToAuthorize))
+			g.Expect(toRevoke).To(Equal(tt.wantToRevoke))
+		})
+	}
+}
+
+func TestGetSecurityGroupIngressRulesSSH(t *testing.T) {
+	tests := []struct {
+		name string
+		ssh  *infrav1alpha1.SSHSpec
+		want infrav1alpha1.IngressRules
+	}{
+		{
+			name: "SSH from any address by default",
+			want: infrav1alpha1.IngressRules{sshRule("0.0.0.0/0")},
+		},
+		{
+			name: "SSH from the allowed CIDRs",
+			ssh:  &infrav1alpha1.SSHSpec{AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}},
+			want: infrav1alpha1.IngressRules{
+				sshRule("10.0.0.0/8"),
+				{
+					Description:    "SSH",
+					Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+					PortRangeMin:   22,
+					PortRangeMax:   22,
+					IPv6CidrBlocks: []string{"2001:db8::/32"},
+				},
+			},
+		},
+		{
+			name: "SSH disabled",
+			ssh:  &infrav1alpha1.SSHSpec{Disabled: true, AllowedCIDRs: []string{"10.0.0.0/8"}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := newTestService(infrav1alpha1.HuaweiCloudClusterSpec{SSH: tt.ssh})
+
+			for _, role := range []infrav1alpha1.SecurityGroupRole{infrav1alpha1.SecurityGroupControlPlane, infrav1alpha1.SecurityGroupNode} {
+				rules, err := s.getSecurityGroupIngressRules(role)
+				g.Expect(err).NotTo(HaveOccurred())
+				expanded, err := s.expandIngressRules(rules)
+				g.Expect(err).NotTo(HaveOccurred())
+
+				var ssh infrav1alpha1.IngressRules
+				for _, rule := range expanded {
+					if rule.PortRangeMin == 22 {
+						ssh = append(ssh, rule)
+					}
+				}
+				g.Expect(ssh).To(Equal(tt.want), "role %s", role)
+			}
+		})
+	}
+}
+
+func TestSSHRuleRevokedWhenDisabled(t *testing.T) {
+	g := NewWithT(t)
+	s := newTestService(infrav1alpha1.HuaweiCloudClusterSpec{SSH: &infrav1alpha1.SSHSpec{Disabled: true}})
+
+	rules, err := s.getSecurityGroupIngressRules(infrav1alpha1.SecurityGroupNode)
+	g.Expect(err).NotTo(HaveOccurred())
+	expanded, err := s.expandIngressRules(rules)
+	g.Expect(err).NotTo(HaveOccurred())
+	desiredIPv4, _ := s.ingressRulesByEthertype(expanded)
+
+	actual := infrav1alpha1.IngressRules{
+		ingressRuleFromSDK(model.SecurityGroupRule{
+			Id:             "ssh",
+			Description:    "SSH",
+			Ethertype:      "IPv4",
+			Protocol:       "tcp",
+			PortRangeMin:   22,
+			PortRangeMax:   22,
+			RemoteIpPrefix: "0.0.0.0/0",
+		}),
+	}
+	_, toRevoke := ingressRulesChanges(desiredIPv4, actual)
+	g.Expect(toRevoke).To(Equal([]int{0}))
+}
+
+func TestIngressRuleSDKRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		ethertype model.NeutronCreateSecurityGroupRuleOptionEthertype
+		rule      infrav1alpha1.IngressRule
+	}{
+		{
+			name:      "IPv4 CIDR",
+			ethertype: model.GetNeutronCreateSecurityGroupRuleOptionEthertypeEnum().I_PV4,
+			rule:      sshRule("10.0.0.0/8"),
+		},
+		{
+			name:      "IPv6 CIDR",
+			ethertype: model.GetNeutronCreateSecurityGroupRuleOptionEthertypeEnum().I_PV6,
+			rule: infrav1alpha1.IngressRule{
+				Description:    "Kubernetes API",
+				Protocol:       infrav1alpha1.SecurityGroupProtocolTCP,
+				PortRangeMin:   6443,
+				PortRangeMax:   6443,
+				IPv6CidrBlocks: []string{"::/0"},
+			},
+		},
+		{
+			name:      "all protocols from a security group",
+			ethertype: model.GetNeutronCreateSecurityGroupRuleOptionEthertypeEnum().I_PV6,
+			rule: infrav1alpha1.IngressRule{
+				Protocol:               infrav1alpha1.SecurityGroupProtocolAll,
+				SourceSecurityGroupIDs: []string{"sg-node"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			s := newTestService(infrav1alpha1.HuaweiCloudClusterSpec{})
+
+			option := s.ingressRuleToSDK("sg-controlplane", tt.ethertype, tt.rule)
+			actual := ingressRuleFromSDK(model.SecurityGroupRule{
+				Description:    ptr.Deref(option.Description, ""),
+				Ethertype:      option.Ethertype.Value(),
+				Protocol:       ptr.Deref(option.Protocol, ""),
+				PortRangeMin:   ptr.Deref(option.PortRangeMin, 0),
+				PortRangeMax:   ptr.Deref(option.PortRangeMax, 0),
+				RemoteIpPrefix: ptr.Deref(option.RemoteIpPrefix, ""),
+				RemoteGroupId:  ptr.Deref(option.RemoteGroupId, ""),
+			})
+			g.Expect(actual.Equals(&tt.rule)).To(BeTrue(), "got %+v", actual)
+		})
+	}
+}